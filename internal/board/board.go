@@ -87,6 +87,15 @@ func (brd Board) Copy() *Board {
 	return &drb
 }
 
+// Restore replaces the pieces with drb's pieces. Unlike Copy, brd keeps it's move event listeners.
+func (brd *Board) Restore(drb *Board) {
+	if drb == nil {
+		return
+	}
+
+	brd.data = drb.data
+}
+
 // String method returns a string. makes it easier to debug
 func (brd Board) String() (str string) {
 	for i := 0; i < (8 * 8); i++ {
@@ -403,20 +412,19 @@ func (brd Board) FinalCheckmate(p1 bool) bool {
 
 			// get all possible moves for that specific piece
 			ps, _ := brd.Possib(id)
-			oldpos := pec.Pos
 			for _, pnt := range ps {
 				// s.Pos = v
 				// using move instead of Set is because move has extra game-logic, that Set could break.
 				// like for example pawns
 				if brd.canMove(id, pnt) {
-					brd.Set(id, pnt)
-					if !brd.Checkmate(p1) {
+					// try every move on a copy, so that captures and the piece's position don't leak into the next move
+					cpy := brd
+					cpy.Set(id, pnt)
+					if !cpy.Checkmate(p1) {
 						return false
 					}
 				}
 			}
-
-			brd.Set(id, oldpos)
 		}
 	}
 
@@ -441,6 +449,23 @@ func (brd Board) Checkmate(p1 bool) bool {
 		return true
 	}
 
+	return len(brd.Checkers(p1)) > 0
+}
+
+// Checkers returns the ids of the enemy pieces that attack the player's king. It's empty whenever the king is not in check, or dead.
+func (brd Board) Checkers(p1 bool) []int8 {
+	ids := []int8{}
+
+	id := GetKing(p1)
+	if id == -1 {
+		return ids
+	}
+
+	king := brd.data[id]
+	if !king.Valid() {
+		return ids
+	}
+
 	enemyids := GetInversePlayer(p1)
 	for _, id := range GetRange(enemyids) {
 		pec := brd.data[id]
@@ -456,12 +481,12 @@ func (brd Board) Checkmate(p1 bool) bool {
 			}
 
 			if ps.In(king.Pos) {
-				return true
+				ids = append(ids, id)
 			}
 		}
 	}
 
-	return false
+	return ids
 }
 
 // i plan to replace this function by the Possib function...
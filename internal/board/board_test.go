@@ -67,6 +67,29 @@ func TestBoardCopy(t *testing.T) {
 	}
 }
 
+func TestBoardRestore(t *testing.T) {
+	brd := NewBoard()
+	drb := brd.Copy()
+
+	called := false
+	brd.Listen(func(_ int8, _ Piece, _, _ Point) {
+		called = true
+	})
+
+	brd.Move(19, Point{3, 4})
+	brd.Restore(drb)
+
+	if brd.String() != drb.String() {
+		t.Fatalf("board.Restore does not restore the pieces")
+	}
+
+	called = false
+	brd.Move(19, Point{3, 4})
+	if !called {
+		t.Fatalf("board.Restore removes the move event listeners")
+	}
+}
+
 func TestBoardListen(t *testing.T) {
 	b := NewBoard()
 
@@ -577,3 +600,26 @@ func TestBoardCheckmate(t *testing.T) {
 		}
 	}
 }
+
+func TestBoardCheckers(t *testing.T) {
+	brd := NewBoard()
+	if len(brd.Checkers(true)) != 0 {
+		t.Fatalf("checkers in the starting position")
+	}
+
+	// fool's mate
+	try := func(id int8, dst Point) {
+		if !brd.Move(id, dst) {
+			t.Fatalf("brd.Move: %d to %s", id, dst.Notation())
+		}
+	}
+	try(21, Point{5, 5})
+	try(12, Point{4, 3})
+	try(22, Point{6, 4})
+	try(3, Point{7, 4})
+
+	ids := brd.Checkers(true)
+	if len(ids) != 1 || ids[0] != 3 {
+		t.Fatalf("checkers - want: [3] | have: %v", ids)
+	}
+}
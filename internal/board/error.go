@@ -8,4 +8,5 @@ var (
 	ErrInvalidID    = errors.New("id is invalid")
 	ErrInvalidPoint = errors.New("invalid point")
 	ErrEmptyPiece   = errors.New("piece is empty")
+	ErrInvalidFEN   = errors.New("invalid fen")
 )
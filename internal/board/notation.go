@@ -0,0 +1,248 @@
+package board
+
+// notation.go is a file containing conversion functions between the board and standard chess notation(algebraic squares, FEN).
+
+import (
+	"strings"
+)
+
+var fenKinds = map[rune]uint8{
+	'p': Pawn,
+	'b': Bishop,
+	'n': Knight,
+	'r': Rook,
+	'q': Queen,
+	'k': King,
+}
+
+// fenString returns the FEN character of the piece, uppercase for player 1(white).
+func fenString(pec Piece) string {
+	for char, kind := range fenKinds {
+		if kind == pec.Kind {
+			if pec.P1 {
+				return strings.ToUpper(string(char))
+			}
+			return string(char)
+		}
+	}
+
+	return ""
+}
+
+// Notation returns the algebraic notation of the point, e.g. {4, 6} is "e2".
+// Note: Player 1 is white, and starts in Y {6, 7} which is rank 2 and rank 1.
+func (p Point) Notation() string {
+	if !p.Valid() {
+		return ""
+	}
+
+	return string(rune('a'+p.X)) + string(rune('1'+(7-p.Y)))
+}
+
+// ParseNotation parses an algebraic square such as "e2" into a point.
+func ParseNotation(str string) (Point, error) {
+	if len(str) != 2 {
+		return Point{}, ErrInvalidPoint
+	}
+
+	pnt := Point{
+		X: int8(str[0]) - 'a',
+		Y: 7 - (int8(str[1]) - '1'),
+	}
+	if !pnt.Valid() {
+		return Point{}, ErrInvalidPoint
+	}
+
+	return pnt, nil
+}
+
+// Position is a board accompanied by the state that FEN describes.
+type Position struct {
+	Brd *Board
+	// P1 is true whenever it's player 1's(white) turn
+	P1 bool
+	// Castling is whether each player could still castle
+	Castling map[bool]bool
+}
+
+// ParseFEN parses the first fields of a FEN string(placement, turn and castling) into a Position.
+//
+// Pieces keep their usual ids whenever possible. Any extra piece(i.e a second queen) takes the place of a captured pawn, the same way a promotion does.
+func ParseFEN(fen string) (Position, error) {
+	fields := strings.Fields(fen)
+	if len(fields) == 0 {
+		return Position{}, ErrInvalidFEN
+	}
+
+	rows := strings.Split(fields[0], "/")
+	if len(rows) != 8 {
+		return Position{}, ErrInvalidFEN
+	}
+
+	brd := NewBoard()
+	for k := range brd.data {
+		brd.data[k].Pos = Point{-1, -1}
+	}
+
+	// used is a map of ids that have been placed in the board
+	used := map[int8]bool{}
+	place := func(p1 bool, kind uint8, pos Point) bool {
+		ids := []int8{}
+		switch kind {
+		case King:
+			ids = append(ids, GetKing(p1))
+		case Queen:
+			ids = append(ids, GetQueen(p1))
+		case Rook:
+			x := GetRooks(p1)
+			ids = append(ids, x[:]...)
+		case Bishop:
+			x := GetBishops(p1)
+			ids = append(ids, x[:]...)
+		case Knight:
+			x := GetKnights(p1)
+			ids = append(ids, x[:]...)
+		}
+
+		// pawns and extra pieces go in the pawn row
+		x := GetRangePawn(p1)
+		ids = append(ids, x[:]...)
+
+		for _, id := range ids {
+			if !used[id] {
+				used[id] = true
+				brd.data[id] = Piece{
+					P1:   p1,
+					Kind: kind,
+					Pos:  pos,
+				}
+				return true
+			}
+		}
+
+		return false
+	}
+
+	for y, row := range rows {
+		x := int8(0)
+		for _, char := range row {
+			if char >= '1' && char <= '8' {
+				x += int8(char - '0')
+				continue
+			}
+
+			kind, ok := fenKinds[char|0x20] // lowercase
+			if !ok || x > 7 {
+				return Position{}, ErrInvalidFEN
+			}
+
+			p1 := char >= 'A' && char <= 'Z'
+			if !place(p1, kind, Point{x, int8(y)}) {
+				return Position{}, ErrInvalidFEN
+			}
+
+			x++
+		}
+
+		if x != 8 {
+			return Position{}, ErrInvalidFEN
+		}
+	}
+
+	if !used[GetKing(true)] || !used[GetKing(false)] {
+		return Position{}, ErrInvalidFEN
+	}
+
+	pos := Position{
+		Brd: brd,
+		P1:  true,
+		Castling: map[bool]bool{
+			true:  false,
+			false: false,
+		},
+	}
+
+	if len(fields) > 1 {
+		switch fields[1] {
+		case "w":
+			pos.P1 = true
+		case "b":
+			pos.P1 = false
+		default:
+			return Position{}, ErrInvalidFEN
+		}
+	}
+
+	if len(fields) > 2 {
+		pos.Castling[true] = strings.ContainsAny(fields[2], "KQ")
+		pos.Castling[false] = strings.ContainsAny(fields[2], "kq")
+	}
+
+	return pos, nil
+}
+
+// FEN returns the position as a FEN string. En passant, and the move counters aren't tracked so they're always "- 0 1".
+func (pos Position) FEN() string {
+	placement := ""
+	for y := int8(0); y < 8; y++ {
+		if y > 0 {
+			placement += "/"
+		}
+
+		empty := 0
+		for x := int8(0); x < 8; x++ {
+			_, pec, err := pos.Brd.Get(Point{x, y})
+			if err != nil {
+				empty++
+				continue
+			}
+
+			if empty > 0 {
+				placement += string(rune('0' + empty))
+				empty = 0
+			}
+			placement += fenString(pec)
+		}
+
+		if empty > 0 {
+			placement += string(rune('0' + empty))
+		}
+	}
+
+	turn := "b"
+	if pos.P1 {
+		turn = "w"
+	}
+
+	castling := ""
+	for _, p1 := range []bool{true, false} {
+		if !pos.Castling[p1] {
+			continue
+		}
+
+		row := GetStartRow(p1)
+		king, _ := pos.Brd.GetByIndex(GetKing(p1))
+		if !king.Pos.Equal(Point{4, row}) {
+			continue
+		}
+
+		for _, side := range []struct {
+			x    int8
+			char string
+		}{{7, "K"}, {0, "Q"}} {
+			_, pec, err := pos.Brd.Get(Point{side.x, row})
+			if err == nil && pec.Kind == Rook && pec.P1 == p1 {
+				if p1 {
+					castling += side.char
+				} else {
+					castling += strings.ToLower(side.char)
+				}
+			}
+		}
+	}
+	if len(castling) == 0 {
+		castling = "-"
+	}
+
+	return placement + " " + turn + " " + castling + " - 0 1"
+}
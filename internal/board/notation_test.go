@@ -0,0 +1,99 @@
+package board
+
+import "testing"
+
+func TestPointNotation(t *testing.T) {
+	list := map[string]Point{
+		"a8": {0, 0},
+		"h8": {7, 0},
+		"e2": {4, 6},
+		"e1": {4, 7},
+		"h1": {7, 7},
+	}
+
+	for str, pnt := range list {
+		have := pnt.Notation()
+		if have != str {
+			t.Fatalf("Notation: want: %s - have: %s", str, have)
+		}
+
+		res, err := ParseNotation(str)
+		if err != nil {
+			t.Fatalf("ParseNotation: %s", err.Error())
+		}
+		if !res.Equal(pnt) {
+			t.Fatalf("ParseNotation: want: %s - have: %s", pnt, res)
+		}
+	}
+
+	for _, str := range []string{"", "i1", "a9", "a", "a10"} {
+		_, err := ParseNotation(str)
+		if err == nil {
+			t.Fatalf("ParseNotation(%s): invalid notation does not return an error", str)
+		}
+	}
+}
+
+func TestParseFEN(t *testing.T) {
+	pos, err := ParseFEN("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1")
+	if err != nil {
+		t.Fatalf("ParseFEN: %s", err.Error())
+	}
+
+	brd := NewBoard()
+	for k := range brd.data {
+		if brd.data[k] != pos.Brd.data[k] {
+			t.Fatalf("starting position is not the same as NewBoard: %d | want: %s - have: %s", k, brd.data[k], pos.Brd.data[k])
+		}
+	}
+
+	if !pos.P1 || !pos.Castling[true] || !pos.Castling[false] {
+		t.Fatalf("turn or castling is not parsed: %v", pos)
+	}
+
+	// two white queens, black to move
+	pos, err = ParseFEN("4k3/8/8/8/8/8/8/Q2QK3 b - - 0 1")
+	if err != nil {
+		t.Fatalf("ParseFEN: %s", err.Error())
+	}
+
+	if pos.P1 || pos.Castling[true] {
+		t.Fatalf("turn or castling is not parsed: %v", pos)
+	}
+
+	queens := 0
+	for _, id := range GetRange(true) {
+		pec := pos.Brd.data[id]
+		if pec.Valid() && pec.Kind == Queen {
+			queens++
+		}
+	}
+	if queens != 2 {
+		t.Fatalf("want: 2 queens - have: %d", queens)
+	}
+
+	for _, fen := range []string{"", "8/8/8/8/8/8/8/8 w - - 0 1", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP w", "4k3/9/8/8/8/8/8/4K3 w"} {
+		_, err := ParseFEN(fen)
+		if err == nil {
+			t.Fatalf("ParseFEN(%s): invalid fen does not return an error", fen)
+		}
+	}
+}
+
+func TestPositionFEN(t *testing.T) {
+	for _, fen := range []string{
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		"r1bqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBN1 b Qkq - 0 1",
+		"4k3/8/8/8/8/8/8/Q2QK3 b - - 0 1",
+	} {
+		pos, err := ParseFEN(fen)
+		if err != nil {
+			t.Fatalf("ParseFEN: %s", err.Error())
+		}
+
+		have := pos.FEN()
+		if have != fen {
+			t.Fatalf("FEN: want: %s - have: %s", fen, have)
+		}
+	}
+}
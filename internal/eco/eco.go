@@ -0,0 +1,102 @@
+// Package eco provides classification of chess openings, using the codes from the Encyclopaedia of Chess Openings.
+//
+// Openings are matched against a game's moves in long algebraic notation(i.e e2e4), the longest matching sequence wins.
+package eco
+
+import (
+	"bufio"
+	_ "embed"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+//go:embed eco.tsv
+var data string
+
+var (
+	mtx   sync.RWMutex
+	table = map[string]model.Opening{}
+	// depth is the number of moves in the longest opening
+	depth int
+)
+
+func init() {
+	err := Read(strings.NewReader(data))
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Read reads a tab-separated opening table, and replaces the current one. The first line is a header, and each line after it is: eco, name, moves.
+// Moves are separated by spaces.
+func Read(rd io.Reader) error {
+	scanner := bufio.NewScanner(rd)
+
+	tab := map[string]model.Opening{}
+	dep := 0
+	header := true
+	for scanner.Scan() {
+		line := scanner.Text()
+		if header {
+			header = false
+			continue
+		}
+
+		if len(strings.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		cols := strings.Split(line, "\t")
+		if len(cols) != 3 {
+			return ErrFormat
+		}
+
+		moves := strings.Fields(cols[2])
+		if len(moves) == 0 {
+			return ErrFormat
+		}
+
+		if len(moves) > dep {
+			dep = len(moves)
+		}
+
+		tab[strings.Join(moves, " ")] = model.Opening{
+			ECO:  cols[0],
+			Name: cols[1],
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	mtx.Lock()
+	table = tab
+	depth = dep
+	mtx.Unlock()
+
+	return nil
+}
+
+// Classify returns the opening that matches the longest sequence of moves. It returns false if not even the first move matches an opening.
+func Classify(moves []string) (model.Opening, bool) {
+	mtx.RLock()
+	defer mtx.RUnlock()
+
+	max := len(moves)
+	if max > depth {
+		max = depth
+	}
+
+	for i := max; i > 0; i-- {
+		op, ok := table[strings.Join(moves[:i], " ")]
+		if ok {
+			return op, true
+		}
+	}
+
+	return model.Opening{}, false
+}
@@ -0,0 +1,50 @@
+package eco
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	list := map[string]string{
+		"e2e4":                          "B00",
+		"e2e4 c7c5":                     "B20",
+		"e2e4 e7e5 g1f3 b8c6 f1b5 a7a6": "C70",
+		// out of book, keeps the last known opening
+		"e2e4 e7e5 g1f3 b8c6 f1b5 a7a6 h2h3 h7h6": "C70",
+		"d2d4 g8f6 c2c4 e7e6 b1c3 f8b4":           "E20",
+	}
+
+	for moves, want := range list {
+		op, ok := Classify(strings.Fields(moves))
+		if !ok {
+			t.Fatalf("Classify(%s): no opening", moves)
+		}
+
+		if op.ECO != want {
+			t.Fatalf("Classify(%s): want: %s - have: %s(%s)", moves, want, op.ECO, op.Name)
+		}
+	}
+
+	_, ok := Classify([]string{"h2h3"})
+	if ok {
+		t.Fatalf("Classify: unknown opening returns true")
+	}
+
+	_, ok = Classify([]string{})
+	if ok {
+		t.Fatalf("Classify: empty moves returns true")
+	}
+}
+
+func TestRead(t *testing.T) {
+	err := Read(strings.NewReader("eco\tname\tmoves\nA00\tno moves\n"))
+	if err == nil {
+		t.Fatalf("Read: invalid table does not return an error")
+	}
+
+	err = Read(strings.NewReader(data))
+	if err != nil {
+		t.Fatalf("Read: %s", err.Error())
+	}
+}
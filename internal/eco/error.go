@@ -0,0 +1,7 @@
+package eco
+
+import "errors"
+
+var (
+	ErrFormat = errors.New("opening table format is invalid")
+)
@@ -0,0 +1,7 @@
+package friends
+
+import "errors"
+
+var (
+	ErrSelf = errors.New("cannot befriend or block yourself")
+)
@@ -0,0 +1,238 @@
+// Package friends provides the friends and the blocked players of each profile.
+//
+// Friends are followed one way, a player doesn't need their friend's approval. Blocking a player removes them from the friends of each other.
+package friends
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+// File is where friends are saved after every change, empty means they're only kept in memory.
+var File string
+
+// lists are the friends and blocked players of a profile, keyed by key.
+type lists struct {
+	Profile model.Profile            `json:"profile"`
+	Friends map[string]model.Profile `json:"friends"`
+	Blocked map[string]model.Profile `json:"blocked"`
+}
+
+var (
+	mtx sync.RWMutex
+	// players is keyed by key
+	players = map[string]*lists{}
+)
+
+func key(pro model.Profile) string {
+	return pro.Platform + ":" + pro.ID
+}
+
+// get must be called with the write lock held.
+func get(pro model.Profile) *lists {
+	l, ok := players[key(pro)]
+	if !ok {
+		l = &lists{
+			Friends: map[string]model.Profile{},
+			Blocked: map[string]model.Profile{},
+		}
+		players[key(pro)] = l
+	}
+	// keep the latest username and picture
+	if len(pro.Username) > 0 {
+		l.Profile = pro
+	}
+
+	return l
+}
+
+// sorted returns the profiles ordered by username.
+func sorted(m map[string]model.Profile) []model.Profile {
+	sl := make([]model.Profile, 0, len(m))
+	for _, v := range m {
+		sl = append(sl, v)
+	}
+
+	sort.Slice(sl, func(i, j int) bool {
+		if sl[i].Username != sl[j].Username {
+			return sl[i].Username < sl[j].Username
+		}
+		return key(sl[i]) < key(sl[j])
+	})
+
+	return sl
+}
+
+// update calls fn with the lock held, then saves the change.
+func update(pro, vs model.Profile, fn func()) error {
+	if key(pro) == key(vs) {
+		return ErrSelf
+	}
+
+	mtx.Lock()
+	fn()
+	mtx.Unlock()
+
+	return Save()
+}
+
+// Follow adds vs to the friends of pro, and unblocks them.
+func Follow(pro, vs model.Profile) error {
+	return update(pro, vs, func() {
+		l := get(pro)
+		delete(l.Blocked, key(vs))
+		l.Friends[key(vs)] = vs
+	})
+}
+
+// Unfollow removes vs from the friends of pro.
+func Unfollow(pro, vs model.Profile) error {
+	return update(pro, vs, func() {
+		delete(get(pro).Friends, key(vs))
+	})
+}
+
+// Block blocks vs for pro, and removes them from the friends of each other.
+func Block(pro, vs model.Profile) error {
+	return update(pro, vs, func() {
+		l := get(pro)
+		delete(l.Friends, key(vs))
+		l.Blocked[key(vs)] = vs
+
+		delete(get(vs).Friends, key(pro))
+	})
+}
+
+// Unblock unblocks vs for pro.
+func Unblock(pro, vs model.Profile) error {
+	return update(pro, vs, func() {
+		delete(get(pro).Blocked, key(vs))
+	})
+}
+
+// Friends returns the friends of pro.
+func Friends(pro model.Profile) []model.Profile {
+	mtx.RLock()
+	defer mtx.RUnlock()
+
+	l, ok := players[key(pro)]
+	if !ok {
+		return []model.Profile{}
+	}
+
+	return sorted(l.Friends)
+}
+
+// Blocked returns the players blocked by pro.
+func Blocked(pro model.Profile) []model.Profile {
+	mtx.RLock()
+	defer mtx.RUnlock()
+
+	l, ok := players[key(pro)]
+	if !ok {
+		return []model.Profile{}
+	}
+
+	return sorted(l.Blocked)
+}
+
+// Followers returns the players that have pro as a friend.
+func Followers(pro model.Profile) []model.Profile {
+	mtx.RLock()
+	defer mtx.RUnlock()
+
+	sl := []model.Profile{}
+	for _, l := range players {
+		if _, ok := l.Friends[key(pro)]; ok {
+			sl = append(sl, l.Profile)
+		}
+	}
+
+	return sl
+}
+
+// IsFriend returns true whenever vs is a friend of pro.
+func IsFriend(pro, vs model.Profile) bool {
+	mtx.RLock()
+	defer mtx.RUnlock()
+
+	l, ok := players[key(pro)]
+	if !ok {
+		return false
+	}
+
+	_, ok = l.Friends[key(vs)]
+	return ok
+}
+
+// Blocks returns true whenever pro blocked vs.
+func Blocks(pro, vs model.Profile) bool {
+	mtx.RLock()
+	defer mtx.RUnlock()
+
+	l, ok := players[key(pro)]
+	if !ok {
+		return false
+	}
+
+	_, ok = l.Blocked[key(vs)]
+	return ok
+}
+
+// Reset removes every friend and blocked player, it's used by tests.
+func Reset() {
+	mtx.Lock()
+	players = map[string]*lists{}
+	mtx.Unlock()
+}
+
+// Save writes the friends and blocked players of every profile to File.
+func Save() error {
+	if len(File) == 0 {
+		return nil
+	}
+
+	mtx.RLock()
+	body, err := json.Marshal(players)
+	mtx.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	tmp := File + ".tmp"
+	err = os.WriteFile(tmp, body, 0600)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, File)
+}
+
+// Load reads the friends saved in path, and saves them to path from now on. It's not an error if path doesn't exist.
+func Load(path string) error {
+	File = path
+
+	body, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	m := map[string]*lists{}
+	err = json.Unmarshal(body, &m)
+	if err != nil {
+		return err
+	}
+
+	mtx.Lock()
+	players = m
+	mtx.Unlock()
+
+	return nil
+}
@@ -0,0 +1,67 @@
+package friends
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+func TestFriends(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	File = filepath.Join(t.TempDir(), "friends.json")
+	defer func() { File = "" }()
+
+	p1 := model.Profile{ID: "1", Platform: "local", Username: "one"}
+	p2 := model.Profile{ID: "2", Platform: "local", Username: "two"}
+	p3 := model.Profile{ID: "3", Platform: "local", Username: "three"}
+
+	if err := Follow(p1, p1); err != ErrSelf {
+		t.Fatalf("Follow: want: %v - have: %v", ErrSelf, err)
+	}
+
+	Follow(p1, p3)
+	Follow(p1, p2)
+	Follow(p2, p1)
+
+	sl := Friends(p1)
+	if len(sl) != 2 || sl[0] != p3 || sl[1] != p2 {
+		t.Fatalf("unexpected friends: %v", sl)
+	}
+	if !IsFriend(p1, p2) || IsFriend(p3, p1) {
+		t.Fatalf("friends aren't one way")
+	}
+	if sl := Followers(p1); len(sl) != 1 || sl[0] != p2 {
+		t.Fatalf("unexpected followers: %v", sl)
+	}
+
+	// blocking removes the friends of each other
+	Block(p2, p1)
+	if !Blocks(p2, p1) || Blocks(p1, p2) {
+		t.Fatalf("unexpected blocks")
+	}
+	if IsFriend(p1, p2) || IsFriend(p2, p1) {
+		t.Fatalf("blocked players are still friends")
+	}
+
+	Unfollow(p1, p3)
+	if len(Friends(p1)) != 0 {
+		t.Fatalf("unexpected friends: %v", Friends(p1))
+	}
+
+	Reset()
+	err := Load(File)
+	if err != nil {
+		t.Fatalf("Load: %s", err.Error())
+	}
+	if sl := Blocked(p2); len(sl) != 1 || sl[0] != p1 {
+		t.Fatalf("blocks weren't saved: %v", sl)
+	}
+
+	Unblock(p2, p1)
+	if Blocks(p2, p1) {
+		t.Fatalf("Unblock does not unblock")
+	}
+}
@@ -0,0 +1,90 @@
+package game
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/toms1441/chess-server/internal/board"
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+// abandon.go detects whenever the side to move stops playing, so that their opponent could claim victory or a draw.
+
+var (
+	// IdleLimit is how long the side to move could think, before the game is considered abandoned.
+	IdleLimit = time.Minute * 5
+	// DisconnectLimit is how long the side to move could stay disconnected, before the game is considered abandoned.
+	DisconnectLimit = time.Second * 30
+)
+
+// watch restarts the abandonment timer of the side to move. It should be called with g.mtx locked.
+// Correspondence games are never abandoned, as players are expected to be offline between moves.
+func (g *Game) watch(limit time.Duration) {
+	if g.idle != nil {
+		g.idle.Stop()
+	}
+
+	g.abandoned = false
+	if g.done || g.opts.Days > 0 {
+		return
+	}
+
+	turn := g.turn
+	g.idle = time.AfterFunc(limit, func() {
+		g.abandon(turn)
+	})
+}
+
+// watchTurn restarts the abandonment timer of the side to move, with DisconnectLimit whenever they're disconnected. It should be called with g.mtx locked.
+func (g *Game) watchTurn() {
+	if g.away[g.turn] {
+		g.watch(DisconnectLimit)
+	} else {
+		g.watch(IdleLimit)
+	}
+}
+
+// watchConnection restarts the abandonment timer whenever the side to move disconnects or reconnects.
+func (g *Game) watchConnection(c *Client, connected bool) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	g.away[c.p1] = !connected
+	if g.turn != c.p1 || g.abandoned {
+		return
+	}
+
+	g.watchTurn()
+}
+
+// abandon notifies the opponent of p1 that they could claim the game.
+func (g *Game) abandon(p1 bool) {
+	g.mtx.Lock()
+	if g.done || g.turn != p1 {
+		g.mtx.Unlock()
+		return
+	}
+
+	g.abandoned = true
+	vs := g.cs[board.GetInversePlayer(p1)]
+	g.mtx.Unlock()
+
+	body, err := json.Marshal(model.AbandonedOrder{
+		P1: p1,
+	})
+	if err != nil {
+		return
+	}
+
+	g.Update(vs, model.Order{
+		ID:   model.OrAbandoned,
+		Data: body,
+	})
+}
+
+// Abandoned returns true whenever the side to move has abandoned the game.
+func (g *Game) Abandoned() bool {
+	g.mtx.RLock()
+	defer g.mtx.RUnlock()
+	return g.abandoned
+}
@@ -0,0 +1,99 @@
+package game
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/toms1441/chess-server/internal/board"
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+func TestGameAbandonIdle(t *testing.T) {
+	old := IdleLimit
+	IdleLimit = time.Millisecond * 10
+	defer func() { IdleLimit = old }()
+
+	_, c1, c2, ch1, ch2 := newDrainedGame(t, model.GameOptions{})
+
+	body, _ := json.Marshal(model.ClaimOrder{})
+	err := c2.Do(model.Order{ID: model.OrClaim, Data: body})
+	if err != ErrClaimNil {
+		t.Fatalf("cl.Do: claim before abandonment, want: %v - have: %v", ErrClaimNil, err)
+	}
+
+	o := waitFor(t, ch2, model.OrAbandoned)
+	abd := model.AbandonedOrder{}
+	json.Unmarshal(o.Data, &abd)
+	if abd.P1 != c1.P1() {
+		t.Fatalf("abandoned update has the wrong player")
+	}
+
+	// the side that abandoned the game cannot claim it
+	err = c1.Do(model.Order{ID: model.OrClaim, Data: body})
+	if err != ErrClaimNil {
+		t.Fatalf("cl.Do: want: %v - have: %v", ErrClaimNil, err)
+	}
+
+	err = c2.Do(model.Order{ID: model.OrClaim, Data: body})
+	if err != nil {
+		t.Fatalf("cl.Do: %s", err.Error())
+	}
+
+	o = waitFor(t, ch1, model.OrDone)
+	done := model.DoneOrder{}
+	json.Unmarshal(o.Data, &done)
+	if done.Reason != model.DoneWhiteAbandon {
+		t.Fatalf("done reason, want: %d - have: %d", model.DoneWhiteAbandon, done.Reason)
+	}
+
+	if c1.Game() != nil || c2.Game() != nil {
+		t.Fatalf("game is not closed after claim")
+	}
+}
+
+func TestGameAbandonDisconnect(t *testing.T) {
+	old := DisconnectLimit
+	DisconnectLimit = time.Millisecond * 10
+	defer func() { DisconnectLimit = old }()
+
+	g, c1, c2, _, ch2 := newDrainedGame(t, model.GameOptions{})
+
+	c1.Disconnect()
+	waitFor(t, ch2, model.OrAbandoned)
+	if !g.Abandoned() {
+		t.Fatalf("game is not abandoned after DisconnectLimit")
+	}
+
+	body, _ := json.Marshal(model.ClaimOrder{Draw: true})
+	err := c2.Do(model.Order{ID: model.OrClaim, Data: body})
+	if err != nil {
+		t.Fatalf("cl.Do: %s", err.Error())
+	}
+
+	o := waitFor(t, ch2, model.OrDone)
+	done := model.DoneOrder{}
+	json.Unmarshal(o.Data, &done)
+	if done.Reason != model.DoneAbandonDraw {
+		t.Fatalf("done reason, want: %d - have: %d", model.DoneAbandonDraw, done.Reason)
+	}
+}
+
+func TestGameAbandonDisconnectBeforeTurn(t *testing.T) {
+	old := DisconnectLimit
+	DisconnectLimit = time.Millisecond * 10
+	defer func() { DisconnectLimit = old }()
+
+	g, c1, c2, ch1, _ := newDrainedGame(t, model.GameOptions{})
+
+	// black disconnects while it's white's turn, then white moves
+	c2.Disconnect()
+	doMoveOrder(t, c1, 20, board.Point{X: 4, Y: 4})
+
+	o := waitFor(t, ch1, model.OrAbandoned)
+	abd := model.AbandonedOrder{}
+	json.Unmarshal(o.Data, &abd)
+	if abd.P1 != c2.P1() || !g.Abandoned() {
+		t.Fatalf("game is not abandoned after DisconnectLimit")
+	}
+}
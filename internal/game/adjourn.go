@@ -0,0 +1,132 @@
+package game
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+// adjourn.go suspends games whenever both players agree, so that they could be resumed later.
+
+var adjourned = map[string]model.Snapshot{}
+var adjournmtx sync.Mutex
+
+// samePlayer returns true whenever both profiles have the same ID and platform.
+func samePlayer(a, b model.Profile) bool {
+	return a.ID == b.ID && a.Platform == b.Platform
+}
+
+// suspend stops the clock, stores the game's snapshot and ends the game with model.DoneAdjourned. The game gets closed by Client.Do.
+func (g *Game) suspend() error {
+	g.mtx.Lock()
+	g.stopClock()
+	if g.idle != nil {
+		g.idle.Stop()
+	}
+	g.mtx.Unlock()
+
+	s := g.Snapshot()
+
+	adjournmtx.Lock()
+	adjourned[s.ID] = s
+	adjournmtx.Unlock()
+
+	g.end(model.DoneAdjourned)
+
+	body, err := json.Marshal(model.DoneOrder{
+		Reason: model.DoneAdjourned,
+	})
+	if err != nil {
+		return err
+	}
+
+	return g.UpdateAll(model.Order{
+		ID:   model.OrDone,
+		Data: body,
+	})
+}
+
+// Adjourned returns the adjourned games where one of the players has the same ID and platform as pro.
+func Adjourned(pro model.Profile) []model.Snapshot {
+	adjournmtx.Lock()
+	defer adjournmtx.Unlock()
+
+	sl := []model.Snapshot{}
+	for _, s := range adjourned {
+		if samePlayer(s.P1, pro) || samePlayer(s.P2, pro) {
+			sl = append(sl, s)
+		}
+	}
+
+	return sl
+}
+
+// AdjournedGames returns every adjourned game, used to save them across restarts.
+func AdjournedGames() []model.Snapshot {
+	adjournmtx.Lock()
+	defer adjournmtx.Unlock()
+
+	sl := make([]model.Snapshot, 0, len(adjourned))
+	for _, s := range adjourned {
+		sl = append(sl, s)
+	}
+
+	return sl
+}
+
+// AddAdjourned stores an adjourned game, so that it could be resumed via Resume.
+func AddAdjourned(s model.Snapshot) error {
+	if s.Brd == nil || len(s.ID) == 0 {
+		return ErrSnapshotInvalid
+	}
+
+	adjournmtx.Lock()
+	adjourned[s.ID] = s
+	adjournmtx.Unlock()
+
+	return nil
+}
+
+// Resume recreates the adjourned game with id, where cl1 and cl2 need to have the profiles of player one and two.
+// Both clients are sent model.OrGame, model.OrTurn and model.OrHistory, then the clock of the side to move starts.
+func Resume(id string, cl1, cl2 *Client) (*Game, error) {
+	if cl1 == nil || cl2 == nil {
+		return nil, ErrClientNil
+	}
+
+	adjournmtx.Lock()
+	s, ok := adjourned[id]
+	if !ok {
+		adjournmtx.Unlock()
+		return nil, ErrAdjournedNil
+	}
+
+	if !samePlayer(cl1.Profile, s.P1) || !samePlayer(cl2.Profile, s.P2) {
+		adjournmtx.Unlock()
+		return nil, ErrNotPlayer
+	}
+
+	delete(adjourned, id)
+	adjournmtx.Unlock()
+
+	g, err := newGame(cl1, cl2, s.Options, s.ID)
+	if err != nil {
+		AddAdjourned(s)
+		return nil, err
+	}
+
+	g.mtx.Lock()
+	g.restore(s)
+	g.watchTurn()
+	g.mtx.Unlock()
+
+	g.resumeClock()
+
+	err = cl1.sync(g, &cl2.Profile)
+	if err != nil {
+		return g, err
+	}
+
+	return g, cl2.sync(g, &cl1.Profile)
+}
@@ -0,0 +1,83 @@
+package game
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/toms1441/chess-server/internal/board"
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+func TestCommandAdjourn(t *testing.T) {
+	opts := model.GameOptions{TimeControl: model.TimeControl{Base: 60}}
+	g, c1, c2, ch1, ch2 := newDrainedGame(t, opts)
+	c1.Profile = model.Profile{ID: "adjourn1", Platform: "test"}
+	c2.Profile = model.Profile{ID: "adjourn2", Platform: "test"}
+
+	doMoveOrder(t, c1, 20, board.Point{X: 4, Y: 4})
+
+	err := c1.Do(model.Order{ID: model.OrAdjourn})
+	if err != nil {
+		t.Fatalf("cl.Do: %s", err.Error())
+	}
+	waitFor(t, ch2, model.OrAdjourn)
+
+	// the requester cannot accept their own adjournment
+	body, _ := json.Marshal(model.ReplyOrder{Accept: true})
+	err = c1.Do(model.Order{ID: model.OrAdjournReply, Data: body})
+	if err != ErrAdjournPending {
+		t.Fatalf("cl.Do: want: %v - have: %v", ErrAdjournPending, err)
+	}
+
+	id, before := g.ID(), g.brd.String()
+	err = c2.Do(model.Order{ID: model.OrAdjournReply, Data: body})
+	if err != nil {
+		t.Fatalf("cl.Do: %s", err.Error())
+	}
+
+	o := waitFor(t, ch1, model.OrDone)
+	done := model.DoneOrder{}
+	json.Unmarshal(o.Data, &done)
+	if done.Reason != model.DoneAdjourned {
+		t.Fatalf("done reason, want: %d - have: %d", model.DoneAdjourned, done.Reason)
+	}
+
+	if c1.Game() != nil || c2.Game() != nil {
+		t.Fatalf("adjourned game does not free the clients")
+	}
+
+	sl := Adjourned(c2.Profile)
+	if len(sl) != 1 || sl[0].ID != id {
+		t.Fatalf("adjourned game is not stored: %+v", sl)
+	}
+
+	_, err = Resume(id, c2, c1)
+	if err != ErrNotPlayer {
+		t.Fatalf("Resume: swapped players, want: %v - have: %v", ErrNotPlayer, err)
+	}
+
+	r1, w1 := io.Pipe()
+	r2, w2 := io.Pipe()
+	c1.W, c2.W = w1, w2
+	ch1, ch2 = drain(r1), drain(r2)
+
+	x, err := Resume(id, c1, c2)
+	if err != nil {
+		t.Fatalf("Resume: %s", err.Error())
+	}
+	defer x.close()
+
+	waitFor(t, ch1, model.OrGame)
+	waitFor(t, ch2, model.OrHistory)
+
+	if x.ID() != id || x.brd.String() != before || !x.IsTurn(c2) {
+		t.Fatalf("resumed game does not have the same state")
+	}
+	if !x.clock.running {
+		t.Fatalf("resumed game does not start the clock")
+	}
+	if len(Adjourned(c1.Profile)) != 0 {
+		t.Fatalf("resumed game is still adjourned")
+	}
+}
@@ -0,0 +1,84 @@
+package game
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/toms1441/chess-server/internal/board"
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+// chat.go contains the game's chat. Players talk to each other, while spectators have their own room.
+
+var (
+	// MaxChat is the maximum length of a chat message, in characters.
+	MaxChat = 280
+	// ChatInterval is the minimum time between two chat messages from the same client.
+	ChatInterval = time.Second
+)
+
+// Chat sends a message from c. Players' messages are sent to their opponent, and spectators' messages are sent to the other spectators.
+// Every message is kept in the game, see Game.Messages.
+func (g *Game) Chat(c *Client, msg string) error {
+	msg = strings.TrimSpace(msg)
+	if len(msg) == 0 || utf8.RuneCountInString(msg) > MaxChat {
+		return ErrChatLength
+	}
+
+	if time.Since(c.lastChat) < ChatInterval {
+		return ErrChatRate
+	}
+	c.lastChat = time.Now()
+
+	player := g.IsPlayer(c)
+	chat := model.ChatOrder{
+		Message:   msg,
+		P1:        c.p1 && player,
+		Spectator: !player,
+	}
+
+	g.mtx.Lock()
+	g.chat = append(g.chat, chat)
+	g.mtx.Unlock()
+
+	body, err := json.Marshal(chat)
+	if err != nil {
+		return err
+	}
+
+	u := model.Order{
+		ID:   model.OrChat,
+		Data: body,
+	}
+
+	if player {
+		return g.Update(g.cs[board.GetInversePlayer(c.p1)], u)
+	}
+
+	body, err = json.Marshal(u)
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		g.mtx.RLock()
+		for cl := range g.spectators {
+			if cl != c {
+				cl.W.Write(body)
+			}
+		}
+		g.mtx.RUnlock()
+	}()
+
+	return nil
+}
+
+// Messages returns a copy of every chat message sent in the game.
+func (g *Game) Messages() []model.ChatOrder {
+	g.mtx.RLock()
+	defer g.mtx.RUnlock()
+
+	return append([]model.ChatOrder{}, g.chat...)
+}
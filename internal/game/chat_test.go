@@ -0,0 +1,83 @@
+package game
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+func doChatOrder(cl *Client, msg string) error {
+	body, _ := json.Marshal(model.ChatOrder{Message: msg})
+	return cl.Do(model.Order{ID: model.OrChat, Data: body})
+}
+
+func TestCommandChat(t *testing.T) {
+	old := ChatInterval
+	ChatInterval = time.Millisecond * 50
+	defer func() { ChatInterval = old }()
+
+	g, c1, c2, _, ch2 := newDrainedGame(t, model.GameOptions{})
+
+	err := doChatOrder(c1, "  ")
+	if err != ErrChatLength {
+		t.Fatalf("cl.Do: empty message, want: %v - have: %v", ErrChatLength, err)
+	}
+
+	err = doChatOrder(c1, strings.Repeat("a", MaxChat+1))
+	if err != ErrChatLength {
+		t.Fatalf("cl.Do: long message, want: %v - have: %v", ErrChatLength, err)
+	}
+
+	err = doChatOrder(c1, "good luck")
+	if err != nil {
+		t.Fatalf("cl.Do: %s", err.Error())
+	}
+
+	o := waitFor(t, ch2, model.OrChat)
+	chat := model.ChatOrder{}
+	json.Unmarshal(o.Data, &chat)
+	if chat.Message != "good luck" || chat.P1 != c1.P1() || chat.Spectator {
+		t.Fatalf("chat update is invalid: %+v", chat)
+	}
+
+	err = doChatOrder(c1, "have fun")
+	if err != ErrChatRate {
+		t.Fatalf("cl.Do: want: %v - have: %v", ErrChatRate, err)
+	}
+
+	// spectators have their own room
+	rd1, wr1 := io.Pipe()
+	rd2, wr2 := io.Pipe()
+	s1, s2 := &Client{W: wr1}, &Client{W: wr2}
+	sh1, sh2 := drain(rd1), drain(rd2)
+	g.AddSpectator(s1)
+	g.AddSpectator(s2)
+
+	err = doChatOrder(s1, "nice move")
+	if err != nil {
+		t.Fatalf("cl.Do: %s", err.Error())
+	}
+
+	o = waitFor(t, sh2, model.OrChat)
+	chat = model.ChatOrder{}
+	json.Unmarshal(o.Data, &chat)
+	if !chat.Spectator {
+		t.Fatalf("spectator chat update is not from the spectator room")
+	}
+
+	select {
+	case o := <-sh1:
+		if o.ID == model.OrChat {
+			t.Fatalf("spectator receives their own message")
+		}
+	case <-time.After(time.Millisecond * 20):
+	}
+
+	if len(c2.g.Messages()) != 2 {
+		t.Fatalf("game does not keep the chat, want: 2 - have: %d", len(g.Messages()))
+	}
+}
@@ -0,0 +1,47 @@
+package game
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/toms1441/chess-server/internal/board"
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+func TestGameCheck(t *testing.T) {
+	g, c1, c2, _, ch2 := newDrainedGame(t, model.GameOptions{})
+
+	doMoveOrder(t, c1, 20, board.Point{X: 4, Y: 4})
+	doMoveOrder(t, c2, 13, board.Point{X: 5, Y: 2})
+	// Qh5+
+	doMoveOrder(t, c1, 27, board.Point{X: 7, Y: 3})
+
+	o := waitFor(t, ch2, model.OrCheck)
+	chk := model.CheckOrder{}
+	json.Unmarshal(o.Data, &chk)
+	if chk.P1 || chk.King != (board.Point{X: 4, Y: 0}) || len(chk.Pieces) != 1 || chk.Pieces[0] != 27 {
+		t.Fatalf("check update is invalid: %+v", chk)
+	}
+
+	his := g.History()
+	if !his[len(his)-1].Check || his[0].Check {
+		t.Fatalf("history does not flag the check")
+	}
+}
+
+func TestGameCheckmateReason(t *testing.T) {
+	_, c1, c2, ch1, _ := newDrainedGame(t, model.GameOptions{})
+
+	// fool's mate
+	doMoveOrder(t, c1, 21, board.Point{X: 5, Y: 5})
+	doMoveOrder(t, c2, 12, board.Point{X: 4, Y: 3})
+	doMoveOrder(t, c1, 22, board.Point{X: 6, Y: 4})
+	doMoveOrder(t, c2, 3, board.Point{X: 7, Y: 4})
+
+	o := waitFor(t, ch1, model.OrDone)
+	done := model.DoneOrder{}
+	json.Unmarshal(o.Data, &done)
+	if done.Reason != model.DoneBlackMates {
+		t.Fatalf("done reason, want: %d - have: %d", model.DoneBlackMates, done.Reason)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"io"
 	"sync"
+	"time"
 
 	"github.com/toms1441/chess-server/internal/board"
 	"github.com/toms1441/chess-server/internal/model"
@@ -17,28 +18,50 @@ type Client struct {
 	id  string
 	g   *Game
 	mtx sync.RWMutex
+
+	// Profile is the profile of the client's user, used to look up games by player
+	Profile model.Profile
+
+	// disconnected is set whenever the client loses their connection, until they reconnect.
+	disconnected bool
+	// lastChat is when the client last sent a chat message, used for rate limiting.
+	lastChat time.Time
 }
 
+// discard is used as the client's writer while it's disconnected, so that updates don't block the game.
+type discard struct{}
+
+func (discard) Write(b []byte) (int, error) { return len(b), nil }
+func (discard) Close() error                { return nil }
+
 // Do executes a command. It automatically checks if the player is in a game, or if the command's ID is invalid.
-// Use of cbs[cmd.ID] is discouraged.
+// Use of cbs[cmd.ID] is discouraged, and custom commands are added via RegisterCommand.
 func (c *Client) Do(cmd model.Order) error {
-	if c.g == nil {
+	g := c.g
+	if g == nil {
 		return ErrGameNil
 	}
 
-	x, ok := cbs[cmd.ID]
+	x, ok := command(cmd.ID)
 	if !ok {
 		return ErrCommandNil
 	}
 
 	c.mtx.Lock()
-	err := x(c, cmd)
+	g.cmd.Lock()
+	// the game could have ended while waiting for the lock, e.g by the clock
+	err := ErrGameDone
+	if !g.Done() {
+		err = x(c, cmd)
+	}
+	g.cmd.Unlock()
 	c.mtx.Unlock()
 
-	if c.g != nil {
-		if c.g.done { // we cannot do this in switch turn
+	// the game could be closed meanwhile, by its clock for example
+	if g := c.g; g != nil {
+		if g.done { // we cannot do this in switch turn
 			// cause it would freeze the program
-			c.g.close()
+			g.close()
 		}
 	}
 
@@ -74,13 +97,142 @@ func (c *Client) LeaveGame() {
 		return
 	}
 
-	x := c.g.cs[board.GetInversePlayer(c.p1)]
+	g.cmd.Lock()
+	x := g.cs[board.GetInversePlayer(c.p1)]
 	g.Update(x, model.Order{
 		ID:   model.OrDone,
 		Data: body,
 	})
 
-	c.g.close()
+	g.end(reason)
+	g.cmd.Unlock()
+
+	g.close()
+}
+
+// Disconnect marks the client as disconnected, and notifies the opponent with model.OrDisconnected. Any update sent to the client is discarded until it reconnects.
+func (c *Client) Disconnect() {
+	c.mtx.Lock()
+	c.W = discard{}
+	c.disconnected = true
+	g := c.g
+	c.mtx.Unlock()
+
+	if g != nil && g.IsPlayer(c) {
+		g.watchConnection(c, false)
+		g.notifyConnection(c, model.OrDisconnected)
+	}
+}
+
+// Reconnect sets the client's writer, and sends the game's state to the client: model.OrGame, model.OrTurn and model.OrHistory.
+// vs is the opponent's profile, which is sent in model.GameOrder. The opponent gets notified with model.OrReconnected.
+func (c *Client) Reconnect(w io.WriteCloser, vs *model.Profile) error {
+	if w == nil {
+		return ErrClientNil
+	}
+
+	c.mtx.Lock()
+	c.W = w
+	c.disconnected = false
+	g := c.g
+	c.mtx.Unlock()
+
+	if g == nil {
+		return ErrGameNil
+	}
+
+	if g.IsTurn(c) {
+		g.resumeClock()
+	}
+
+	err := c.sync(g, vs)
+	if err != nil {
+		return err
+	}
+
+	if g.IsPlayer(c) {
+		g.watchConnection(c, true)
+		g.notifyConnection(c, model.OrReconnected)
+	}
+
+	return nil
+}
+
+// Sync sends the game's state to w only, such as another connection of the same user: model.OrGame, model.OrTurn and model.OrHistory.
+// vs is the opponent's profile, which is sent in model.GameOrder.
+func (c *Client) Sync(w io.WriteCloser, vs *model.Profile) error {
+	g := c.Game()
+	if g == nil {
+		return ErrGameNil
+	}
+	if w == nil {
+		return ErrClientNil
+	}
+
+	return (&Client{W: w, p1: c.p1}).sync(g, vs)
+}
+
+// sync sends the game's state to the client: model.OrGame, model.OrTurn and model.OrHistory. vs is the opponent's profile.
+func (c *Client) sync(g *Game, vs *model.Profile) error {
+	p1 := c.p1
+	body, err := json.Marshal(model.GameOrder{
+		ID:      g.id,
+		P1:      &p1,
+		Profile: vs,
+		Brd:     g.brd,
+		Opening: g.Opening(),
+	})
+	if err != nil {
+		return err
+	}
+
+	err = g.Update(c, model.Order{
+		ID:   model.OrGame,
+		Data: body,
+	})
+	if err != nil {
+		return err
+	}
+
+	g.mtx.RLock()
+	turn := g.turn
+	g.mtx.RUnlock()
+
+	body, err = json.Marshal(model.TurnOrder{
+		P1:      turn,
+		Clock:   g.Clock(),
+		Opening: g.Opening(),
+	})
+	if err != nil {
+		return err
+	}
+
+	err = g.Update(c, model.Order{
+		ID:   model.OrTurn,
+		Data: body,
+	})
+	if err != nil {
+		return err
+	}
+
+	body, err = json.Marshal(model.HistoryOrder{
+		History: g.History(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return g.Update(c, model.Order{
+		ID:   model.OrHistory,
+		Data: body,
+	})
+}
+
+// Connected returns false whenever the client is disconnected and waiting to reconnect.
+func (c *Client) Connected() bool {
+	c.mtx.RLock()
+	defer c.mtx.RUnlock()
+	return !c.disconnected
 }
 
 // P1 returns if the client is player one or two.
@@ -0,0 +1,159 @@
+package game
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+// clock.go contains the game's clock, which is only used whenever the game has a time control.
+
+type clock struct {
+	remaining map[bool]time.Duration
+	increment time.Duration
+	// turn is the player that has their clock running
+	turn    bool
+	running bool
+	start   time.Time
+	timer   *time.Timer
+	// perMove is the time each side gets per move in correspondence games, zero otherwise.
+	perMove time.Duration
+}
+
+// newClock returns nil if the time control is untimed.
+func newClock(tc model.TimeControl) *clock {
+	if tc.Base <= 0 {
+		return nil
+	}
+
+	base := time.Duration(tc.Base) * time.Second
+	return &clock{
+		remaining: map[bool]time.Duration{
+			true:  base,
+			false: base,
+		},
+		increment: time.Duration(tc.Increment) * time.Second,
+	}
+}
+
+// newMoveClock returns a clock that resets the time of the side to move to perMove after every move, used in correspondence games.
+func newMoveClock(days int) *clock {
+	perMove := time.Duration(days) * time.Hour * 24
+	return &clock{
+		remaining: map[bool]time.Duration{
+			true:  perMove,
+			false: perMove,
+		},
+		perMove: perMove,
+	}
+}
+
+// tick stops the running clock, and starts the clock of next. inc adds the increment to the stopped clock, which is done after a move.
+func (g *Game) tick(next bool, inc bool) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	cl := g.clock
+	if cl == nil || g.done {
+		return
+	}
+
+	if cl.running {
+		cl.timer.Stop()
+		cl.remaining[cl.turn] -= time.Since(cl.start)
+		if inc {
+			cl.remaining[cl.turn] += cl.increment
+		}
+	}
+
+	if inc && cl.perMove > 0 {
+		cl.remaining[next] = cl.perMove
+	}
+
+	cl.turn = next
+	cl.running = true
+	cl.start = time.Now()
+	cl.timer = time.AfterFunc(cl.remaining[next], func() {
+		g.flag(next)
+	})
+}
+
+// stopClock stops the clock without starting the other player's clock. It should be called with g.mtx locked.
+func (g *Game) stopClock() {
+	cl := g.clock
+	if cl == nil || !cl.running {
+		return
+	}
+
+	cl.timer.Stop()
+	cl.remaining[cl.turn] -= time.Since(cl.start)
+	cl.running = false
+}
+
+// flag ends the game whenever p1 runs out of time. It waits for any command in flight, which could have stopped the clock meanwhile.
+func (g *Game) flag(p1 bool) {
+	g.cmd.Lock()
+	g.mtx.Lock()
+	cl := g.clock
+	if g.done || cl == nil || !cl.running || cl.turn != p1 {
+		g.mtx.Unlock()
+		g.cmd.Unlock()
+		return
+	}
+
+	cl.running = false
+	cl.remaining[p1] = 0
+	g.mtx.Unlock()
+
+	reason := model.DoneBlackTimeout
+	if p1 {
+		reason = model.DoneWhiteTimeout
+	}
+	g.end(reason)
+
+	body, err := json.Marshal(model.DoneOrder{
+		Reason: reason,
+	})
+	if err == nil {
+		g.UpdateAll(model.Order{
+			ID:   model.OrDone,
+			Data: body,
+		})
+	}
+	g.cmd.Unlock()
+
+	g.close()
+}
+
+// Clock returns the remaining time of each player, or nil if the game is untimed.
+func (g *Game) Clock() *model.Clock {
+	g.mtx.RLock()
+	defer g.mtx.RUnlock()
+
+	cl := g.clock
+	if cl == nil {
+		return nil
+	}
+
+	p1, p2 := cl.remaining[true], cl.remaining[false]
+	if cl.running {
+		if cl.turn {
+			p1 -= time.Since(cl.start)
+		} else {
+			p2 -= time.Since(cl.start)
+		}
+	}
+
+	if p1 < 0 {
+		p1 = 0
+	}
+	if p2 < 0 {
+		p2 = 0
+	}
+
+	return &model.Clock{
+		P1: p1.Milliseconds(),
+		P2: p2.Milliseconds(),
+	}
+}
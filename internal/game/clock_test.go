@@ -0,0 +1,161 @@
+package game
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/toms1441/chess-server/internal/board"
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+func TestGameClock(t *testing.T) {
+	g, c1, _, ch1, _ := newDrainedGame(t, model.GameOptions{
+		TimeControl: model.TimeControl{
+			Base:      60,
+			Increment: 1,
+		},
+	})
+
+	clk := g.Clock()
+	if clk == nil {
+		t.Fatalf("timed game does not have a clock")
+	}
+	if clk.P2 != 60000 {
+		t.Fatalf("player two's clock is running before player one moved: %d", clk.P2)
+	}
+
+	doMoveOrder(t, c1, 20, board.Point{X: 4, Y: 4})
+
+	clk = g.Clock()
+	if clk.P1 <= 60000 {
+		t.Fatalf("increment is not added to player one's clock: %d", clk.P1)
+	}
+
+	// run player two out of time
+	g.mtx.Lock()
+	g.clock.remaining[false] = time.Millisecond * 5
+	g.mtx.Unlock()
+	g.tick(false, false)
+
+	o := waitFor(t, ch1, model.OrDone)
+	done := model.DoneOrder{}
+	json.Unmarshal(o.Data, &done)
+	if done.Reason != model.DoneBlackTimeout {
+		t.Fatalf("done reason: want: %d - have: %d", model.DoneBlackTimeout, done.Reason)
+	}
+
+	time.Sleep(time.Millisecond * 10)
+	if c1.Game() != nil {
+		t.Fatalf("game is not closed after running out of time")
+	}
+}
+
+func TestGameUntimed(t *testing.T) {
+	g, _, _, _, _ := newDrainedGame(t, model.GameOptions{})
+
+	if g.Clock() != nil {
+		t.Fatalf("untimed game has a clock")
+	}
+
+	if g.Options().Variant != model.VariantStandard {
+		t.Fatalf("default variant: want: %s - have: %s", model.VariantStandard, g.Options().Variant)
+	}
+}
+
+func TestGameCorrespondenceClock(t *testing.T) {
+	g, c1, _, _, _ := newDrainedGame(t, model.GameOptions{
+		TimeControl: model.TimeControl{Base: 60},
+		Days:        3,
+	})
+	defer g.close()
+
+	perMove := time.Hour * 24 * 3
+	if !g.Correspondence() || g.clock == nil || g.clock.perMove != perMove {
+		t.Fatalf("correspondence game does not have days per move")
+	}
+
+	g.mtx.Lock()
+	g.clock.remaining[false] = time.Hour
+	g.mtx.Unlock()
+
+	doMoveOrder(t, c1, 20, board.Point{X: 4, Y: 4})
+
+	clk := g.Clock()
+	if time.Duration(clk.P2)*time.Millisecond <= perMove-time.Second {
+		t.Fatalf("the side to move does not get the days per move: %d", clk.P2)
+	}
+}
+
+type endObserver struct {
+	NopObserver
+	ends int
+}
+
+func (eo *endObserver) OnEnd(g *Game, ev EndEvent) { eo.ends++ }
+
+func TestGameFlagDuringMove(t *testing.T) {
+	g, c1, _, _, _ := newDrainedGame(t, model.GameOptions{
+		TimeControl: model.TimeControl{
+			Base: 60,
+		},
+	})
+
+	eo := &endObserver{}
+	g.Observe(eo)
+
+	// the clock ends the game while player one's move is in flight, then both close it
+	g.end(model.DoneWhiteTimeout)
+	body, _ := json.Marshal(model.MoveOrder{ID: 20, Dst: board.Point{X: 4, Y: 4}})
+	c1.Do(model.Order{ID: model.OrMove, Data: body})
+	g.close()
+
+	select {
+	case <-g.ListenForDone():
+	case <-time.After(time.Millisecond * 100):
+		t.Fatalf("game is not closed")
+	}
+
+	if eo.ends != 1 {
+		t.Fatalf("OnEnd: want: 1 - have: %d", eo.ends)
+	}
+}
+
+func TestGameFlagSerialized(t *testing.T) {
+	g, _, _, ch1, _ := newDrainedGame(t, model.GameOptions{
+		TimeControl: model.TimeControl{
+			Base: 60,
+		},
+	})
+
+	// a command is in flight while player one runs out of time
+	g.cmd.Lock()
+	g.mtx.Lock()
+	g.clock.remaining[true] = time.Millisecond * 5
+	g.mtx.Unlock()
+	g.tick(true, false)
+
+	time.Sleep(time.Millisecond * 20)
+	if g.Done() {
+		g.cmd.Unlock()
+		t.Fatalf("clock ended the game while a command is in flight")
+	}
+	g.cmd.Unlock()
+
+	waitFor(t, ch1, model.OrDone)
+	if !g.Done() {
+		t.Fatalf("game is not done after running out of time")
+	}
+}
+
+func TestCommandAfterEnd(t *testing.T) {
+	g, c1, _, _, _ := newDrainedGame(t, model.GameOptions{})
+
+	g.end(model.DoneWhiteTimeout)
+
+	body, _ := json.Marshal(model.MoveOrder{ID: 20, Dst: board.Point{X: 4, Y: 4}})
+	err := c1.Do(model.Order{ID: model.OrMove, Data: body})
+	if err != ErrGameDone {
+		t.Fatalf("move after the game ended: want: %v - have: %v", ErrGameDone, err)
+	}
+}
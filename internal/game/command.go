@@ -19,8 +19,12 @@ func init() {
 	cbs = map[uint8]CommandCallback{
 		model.OrMove: func(c *Client, o model.Order) error {
 			g := c.g
+			if !g.IsPlayer(c) {
+				return ErrNotPlayer
+			}
+
 			if !g.IsTurn(c) {
-				return ErrIllegalTurn
+				return g.queuePremove(c, o)
 			}
 
 			if c.inPromotion() {
@@ -50,11 +54,13 @@ func init() {
 				return ErrIllegalMove
 			}
 
+			st := g.save()
 			// do the order
 			ret := g.brd.Move(s.ID, s.Dst)
 			if ret == false {
 				return ErrIllegalMove
 			}
+			g.push(st)
 
 			// first off update about the move...
 			err = g.UpdateAll(model.Order{
@@ -110,6 +116,11 @@ func init() {
 				return err
 			}
 
+			g.recordPromotion(s.ID, s.Kind)
+			g.notify(func(o Observer) {
+				o.OnPromotion(g, PromotionEvent{ID: s.ID, Kind: s.Kind, P1: c.p1})
+			})
+
 			err = g.UpdateAll(model.Order{
 				ID: model.OrPromotion,
 				Parameter: model.PromotionOrder{
@@ -150,7 +161,6 @@ func init() {
 			r1, r2 := rid[0], rid[1]
 			src, dst := cast.Src, cast.Dst
 			if (kingid != src && kingid != dst) || src != r1 && dst != r1 && src != r2 && dst != r2 {
-				fmt.Println("debug 3")
 				return ErrIllegalCastling
 			}
 
@@ -187,6 +197,7 @@ func init() {
 				}
 			}
 
+			st := c.g.save()
 			if minx == 4 {
 				brd.Set(rookid, board.Point{5, y})
 				brd.Set(kingid, board.Point{6, y})
@@ -195,6 +206,15 @@ func init() {
 				brd.Set(kingid, board.Point{2, y})
 			}
 
+			c.g.push(st)
+
+			moved, _ := brd.GetByIndex(kingid)
+			c.g.record(model.History{
+				ID:  kingid,
+				Src: pecking.Pos,
+				Dst: moved.Pos,
+			})
+
 			body, err := json.Marshal(model.CastlingOrder{
 				Src: kingid,
 				Dst: rookid,
@@ -211,14 +231,200 @@ func init() {
 				return err
 			}
 
+			c.g.notify(func(o Observer) {
+				o.OnCastle(c.g, CastleEvent{P1: c.p1, King: kingid, Rook: rookid})
+			})
+
 			c.g.SwitchTurn()
 
 			return nil
 		},
+		model.OrTakeback: func(c *Client, o model.Order) error {
+			g := c.g
+			if !g.IsPlayer(c) {
+				return ErrNotPlayer
+			}
+
+			if g.opts.Rated {
+				return ErrTakebackRated
+			}
+
+			g.mtx.Lock()
+			// the requester needs a move of their own to take back
+			if len(g.states) < g.plies(c.p1) {
+				g.mtx.Unlock()
+				return ErrTakebackNil
+			}
+			g.takeback = c
+			g.mtx.Unlock()
+
+			body, err := json.Marshal(model.TakebackOrder{
+				P1: c.p1,
+			})
+			if err != nil {
+				return err
+			}
+
+			return g.Update(g.cs[board.GetInversePlayer(c.p1)], model.Order{
+				ID:   model.OrTakeback,
+				Data: body,
+			})
+		},
+		model.OrTakebackReply: func(c *Client, o model.Order) error {
+			g := c.g
+			if !g.IsPlayer(c) {
+				return ErrNotPlayer
+			}
+
+			reply := model.ReplyOrder{}
+			err := json.Unmarshal(o.Data, &reply)
+			// unmarshal the order
+			if err != nil {
+				return fmt.Errorf("json.Unmarshal: %w", err)
+			}
+
+			g.mtx.Lock()
+			req := g.takeback
+			if req == nil || req == c {
+				g.mtx.Unlock()
+				return ErrTakebackPending
+			}
+			g.takeback = nil
+			n := g.plies(req.p1)
+			g.mtx.Unlock()
+
+			if !reply.Accept {
+				body, err := json.Marshal(reply)
+				if err != nil {
+					return err
+				}
+
+				return g.Update(req, model.Order{
+					ID:   model.OrTakebackReply,
+					Data: body,
+				})
+			}
+
+			return g.revert(n)
+		},
+		model.OrAdjourn: func(c *Client, o model.Order) error {
+			g := c.g
+			if !g.IsPlayer(c) {
+				return ErrNotPlayer
+			}
+
+			g.mtx.Lock()
+			g.adjourn = c
+			g.mtx.Unlock()
+
+			body, err := json.Marshal(model.AdjournOrder{
+				P1: c.p1,
+			})
+			if err != nil {
+				return err
+			}
+
+			return g.Update(g.cs[board.GetInversePlayer(c.p1)], model.Order{
+				ID:   model.OrAdjourn,
+				Data: body,
+			})
+		},
+		model.OrAdjournReply: func(c *Client, o model.Order) error {
+			g := c.g
+			if !g.IsPlayer(c) {
+				return ErrNotPlayer
+			}
+
+			reply := model.ReplyOrder{}
+			err := json.Unmarshal(o.Data, &reply)
+			// unmarshal the order
+			if err != nil {
+				return fmt.Errorf("json.Unmarshal: %w", err)
+			}
+
+			g.mtx.Lock()
+			req := g.adjourn
+			if req == nil || req == c {
+				g.mtx.Unlock()
+				return ErrAdjournPending
+			}
+			g.adjourn = nil
+			g.mtx.Unlock()
+
+			if !reply.Accept {
+				body, err := json.Marshal(reply)
+				if err != nil {
+					return err
+				}
+
+				return g.Update(req, model.Order{
+					ID:   model.OrAdjournReply,
+					Data: body,
+				})
+			}
+
+			return g.suspend()
+		},
+		model.OrClaim: func(c *Client, o model.Order) error {
+			g := c.g
+			if !g.IsPlayer(c) {
+				return ErrNotPlayer
+			}
+
+			claim := model.ClaimOrder{}
+			err := json.Unmarshal(o.Data, &claim)
+			// unmarshal the order
+			if err != nil {
+				return fmt.Errorf("json.Unmarshal: %w", err)
+			}
+
+			g.mtx.Lock()
+			if !g.abandoned || g.turn == c.p1 {
+				g.mtx.Unlock()
+				return ErrClaimNil
+			}
+			g.mtx.Unlock()
+
+			reason := model.DoneAbandonDraw
+			if !claim.Draw {
+				if c.p1 {
+					reason = model.DoneBlackAbandon
+				} else {
+					reason = model.DoneWhiteAbandon
+				}
+			}
+			g.end(reason)
+
+			body, err := json.Marshal(model.DoneOrder{
+				Reason: reason,
+			})
+			if err != nil {
+				return err
+			}
+
+			return g.UpdateAll(model.Order{
+				ID:   model.OrDone,
+				Data: body,
+			})
+		},
+		model.OrChat: func(c *Client, o model.Order) error {
+			chat := model.ChatOrder{}
+			err := json.Unmarshal(o.Data, &chat)
+			// unmarshal the order
+			if err != nil {
+				return fmt.Errorf("json.Unmarshal: %w", err)
+			}
+
+			return c.g.Chat(c, chat.Message)
+		},
 		model.OrDone: func(c *Client, o model.Order) error {
 			oth := board.GetInversePlayer(c.p1)
 
-			c.g.done = true
+			if oth {
+				c.g.end(model.DoneWhiteWon)
+			} else {
+				c.g.end(model.DoneBlackWon)
+			}
 
 			return c.g.UpdateAll(model.Order{
 				ID:        model.OrDone,
@@ -6,6 +6,7 @@ var (
 	ErrClientNil        = errors.New("client is nil")
 	ErrGameNil          = errors.New("game is nil")
 	ErrGameIsNotNil     = errors.New("game isnt nil")
+	ErrGameDone         = errors.New("game is over")
 	ErrCommandNil       = errors.New("command is nil")
 	ErrPieceNil         = errors.New("piece is nil")
 	ErrIllegalTurn      = errors.New("illegal turn")
@@ -17,4 +18,17 @@ var (
 	ErrUpdateNil        = errors.New("update is nil")
 	ErrUpdateTimeout    = errors.New("update write timeout")
 	ErrUpdateParameter  = errors.New("update parameter is invalid")
+	ErrNotPlayer        = errors.New("client is not a player in the game")
+	ErrTakebackRated    = errors.New("takebacks are not allowed in rated games")
+	ErrTakebackNil      = errors.New("there is no move to take back")
+	ErrTakebackPending  = errors.New("there is no pending takeback")
+	ErrClaimNil         = errors.New("cannot claim, the opponent has not abandoned the game")
+	ErrChatLength       = errors.New("chat message is empty or too long")
+	ErrChatRate         = errors.New("sending chat messages too fast. please wait")
+	ErrSnapshotInvalid  = errors.New("snapshot is invalid")
+	ErrHandicapInvalid  = errors.New("handicap is invalid")
+	ErrOrderReserved    = errors.New("order id is reserved, use an id starting from model.OrCustom")
+	ErrOrderRegistered  = errors.New("order id is already registered")
+	ErrAdjournPending   = errors.New("there is no pending adjournment")
+	ErrAdjournedNil     = errors.New("adjourned game does not exist")
 )
@@ -3,12 +3,15 @@ package game
 import (
 	"encoding/json"
 	"sync"
+	"time"
 
 	"github.com/toms1441/chess-server/internal/board"
 	"github.com/toms1441/chess-server/internal/model"
 )
 
 type Game struct {
+	// id is the game's ID in the registry
+	id string
 	// cs is a map of p1 and !p1 linking them to a client pointer
 	cs map[bool]*Client
 	// turn is a flip flop of p1. SwitchTurn
@@ -23,16 +26,61 @@ type Game struct {
 	canCastle map[bool]bool
 	// spectators is a map of ids assigned to io writers.
 	// Spectators cannot send commands, and only have access to the following updates:
-	// OrMove, OrTurn, OrPromotion, OrCastling, OrCheckmate, OrDone
+	// OrMove, OrTurn, OrPromotion, OrCastling, OrCheck, OrDone
 	// All spectator operations should be non-blocking, and should be ignored if they fail
 	spectators map[*Client]struct{}
-	mtx        sync.RWMutex
+	// history is the list of moves played in the game
+	history []model.History
+	// opening is the opening classified from history, nil if it's unknown.
+	opening *model.Opening
+	// states is the state of the game before each move, used for takebacks.
+	states []state
+	// takeback is the client that requested a takeback, nil if there's none.
+	takeback *Client
+	// clock is nil whenever the game is untimed
+	clock *clock
+	opts  model.GameOptions
+	mtx   sync.RWMutex
+	// cmd serializes the commands of both players, so that the game is changed by one command at a time. Premoves fire while the opponent's command holds it.
+	cmd sync.Mutex
+
+	// idle is the abandonment timer of the side to move.
+	idle *time.Timer
+	// abandoned is set whenever the side to move has been idle or disconnected beyond the limit.
+	abandoned bool
+	// away is whether each player is disconnected, it's kept apart from the clients so that it could be read with g.mtx locked.
+	away map[bool]bool
+	// chat is every message sent by the players and spectators.
+	chat []model.ChatOrder
+	// premove is the move queued by the side not to move, nil if there's none.
+	premove *premove
+	// observers are notified of the game's lifecycle, in addition to the global observers.
+	observers []Observer
+	// started is set once the first turn begins
+	started bool
+	// reason is why the game ended, one of model.Done*
+	reason uint8
+	// adjourn is the client that requested an adjournment, nil if there's none.
+	adjourn *Client
+	// start is the FEN of the starting position, after the handicap is applied.
+	start string
+	// closed is set once the game is closed, as a game could end from a command and its clock at once.
+	closed bool
 }
 
 // NewGame creates a game for client 1 and client 2(cl1, cl2). It fails whenever the clients are already in a game, or one of them is nil.
 // Note: you need to call game.SwitchTurn() to get an actual game going, as NewGame does not update the clients with the player turn.
 func NewGame(cl1, cl2 *Client) (*Game, error) {
+	return NewGameWithOptions(cl1, cl2, model.GameOptions{})
+}
+
+// NewGameWithOptions is the same as NewGame, but with custom game options.
+func NewGameWithOptions(cl1, cl2 *Client, opts model.GameOptions) (*Game, error) {
+	return newGame(cl1, cl2, opts, "")
+}
 
+// newGame creates the game with id, a new id is assigned if it's empty.
+func newGame(cl1, cl2 *Client, opts model.GameOptions, id string) (*Game, error) {
 	if cl1 == nil || cl2 == nil || cl1.W == nil || cl2.W == nil {
 		return nil, ErrClientNil
 	}
@@ -41,28 +89,75 @@ func NewGame(cl1, cl2 *Client) (*Game, error) {
 		return nil, ErrGameIsNotNil
 	}
 
+	pos := board.Position{
+		Brd: board.NewBoard(),
+		P1:  true,
+		Castling: map[bool]bool{
+			true:  true,
+			false: true,
+		},
+	}
+	if len(opts.FEN) > 0 {
+		var err error
+		pos, err = board.ParseFEN(opts.FEN)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := applyHandicap(&pos, opts.Handicap)
+	if err != nil {
+		return nil, err
+	}
+
 	cl1.p1 = true
 	cl2.p1 = false
 
+	if len(opts.Variant) == 0 {
+		opts.Variant = model.VariantStandard
+	}
+
 	g := &Game{
+		id: id,
 		cs: map[bool]*Client{
 			true:  cl1,
 			false: cl2,
 		},
-		turn: false,
-		canCastle: map[bool]bool{
-			true:  true,
-			false: true,
-		},
+		// SwitchTurn flips the turn to the side to move
+		turn:       !pos.P1,
+		canCastle:  pos.Castling,
 		listenDone: make(chan struct{}),
 		spectators: map[*Client]struct{}{},
+		away:       map[bool]bool{},
+		clock:      newClock(opts.TimeControl),
+		opts:       opts,
+		start:      pos.FEN(),
+	}
+
+	if opts.Days > 0 {
+		g.clock = newMoveClock(opts.Days)
+	}
+
+	if g.clock != nil && opts.Handicap != nil {
+		g.clock.remaining[!opts.Handicap.P1] += time.Duration(opts.Handicap.Time) * time.Second
 	}
 
 	cl1.g, cl2.g = g, g
+	register(g)
 
-	g.brd = board.NewBoard()
+	g.brd = pos.Brd
 
 	g.brd.Listen(func(id int8, p board.Piece, src board.Point, dst board.Point) {
+		g.record(model.History{
+			ID:  id,
+			Src: src,
+			Dst: dst,
+		})
+
+		g.notify(func(o Observer) {
+			o.OnMove(g, MoveEvent{ID: id, Piece: p, Src: src, Dst: dst})
+		})
+
 		if p.Kind == board.Pawn {
 			if dst.Y == 7 || dst.Y == 0 {
 				c := g.cs[p.P1]
@@ -96,12 +191,22 @@ func (g *Game) SwitchTurn() {
 	// well, do we have a final checkmate on the other player?
 	if g.brd.FinalCheckmate(aft) {
 		// if so, gg
+		g.recordCheck()
+
+		reason := model.DoneBlackMates
+		if bef {
+			reason = model.DoneWhiteMates
+		}
+
+		body, _ := json.Marshal(model.DoneOrder{
+			Reason: reason,
+		})
 		g.UpdateAll(model.Order{
-			ID:        model.OrDone,
-			Parameter: bef,
+			ID:   model.OrDone,
+			Data: body,
 		})
 
-		g.done = true
+		g.end(reason)
 
 		return
 	}
@@ -109,20 +214,57 @@ func (g *Game) SwitchTurn() {
 	// change the turn
 	g.mtx.Lock()
 	g.turn = aft
+	g.watchTurn()
+	start := !g.started
+	g.started = true
 	g.mtx.Unlock()
 
+	if start {
+		g.notify(func(o Observer) {
+			o.OnStart(g)
+		})
+	}
+
+	g.tick(aft, true)
+
 	x, _ := json.Marshal(model.TurnOrder{
-		P1: aft,
+		P1:      aft,
+		Clock:   g.Clock(),
+		Opening: g.Opening(),
 	})
 
+	// Checkmate is true whenever the king is in check
 	if g.brd.Checkmate(aft) {
+		g.check(aft)
+	}
+
+	g.UpdateAll(model.Order{ID: model.OrTurn, Data: x})
+
+	g.firePremove(aft)
+}
+
+// check notifies everyone that p1's king is in check, and records it in the history.
+func (g *Game) check(p1 bool) {
+	g.recordCheck()
+
+	king, _ := g.brd.GetByIndex(board.GetKing(p1))
+	pieces := g.brd.Checkers(p1)
+
+	body, err := json.Marshal(model.CheckOrder{
+		P1:     p1,
+		King:   king.Pos,
+		Pieces: pieces,
+	})
+	if err == nil {
 		g.UpdateAll(model.Order{
-			ID:        model.OrCheckmate,
-			Parameter: aft,
+			ID:   model.OrCheck,
+			Data: body,
 		})
 	}
 
-	g.UpdateAll(model.Order{ID: model.OrTurn, Data: x})
+	g.notify(func(o Observer) {
+		o.OnCheck(g, CheckEvent{P1: p1, King: king.Pos, Pieces: pieces})
+	})
 }
 
 // IsTurn returns if it's the client's turn this time
@@ -134,6 +276,36 @@ func (g *Game) IsTurn(c *Client) bool {
 	return c.p1 == g.turn
 }
 
+// IsPlayer returns if the client is one of the game's players, rather than a spectator.
+func (g *Game) IsPlayer(c *Client) bool {
+	if c == nil {
+		return false
+	}
+
+	g.mtx.RLock()
+	defer g.mtx.RUnlock()
+	return g.cs[c.p1] == c
+}
+
+// notifyConnection sends id to c's opponent, with the player that got disconnected or reconnected.
+func (g *Game) notifyConnection(c *Client, id uint8) {
+	body, err := json.Marshal(model.DisconnectedOrder{
+		P1: c.p1,
+	})
+	if err != nil {
+		return
+	}
+
+	g.mtx.RLock()
+	vs := g.cs[board.GetInversePlayer(c.p1)]
+	g.mtx.RUnlock()
+
+	g.Update(vs, model.Order{
+		ID:   id,
+		Data: body,
+	})
+}
+
 // Update is used to send updates to the client, such as a movement of a piece.
 func (g *Game) Update(c *Client, u model.Order) error {
 	if c == nil {
@@ -141,7 +313,7 @@ func (g *Game) Update(c *Client, u model.Order) error {
 	}
 
 	if u.Data == nil {
-		x, ok := ubs[u.ID]
+		x, ok := update(u.ID)
 		if !ok {
 			return ErrUpdateNil
 		}
@@ -152,6 +324,7 @@ func (g *Game) Update(c *Client, u model.Order) error {
 		}
 	}
 
+	u.Game = g.id
 	body, err := json.Marshal(u)
 	if err != nil {
 		return err
@@ -170,7 +343,7 @@ func (g *Game) UpdateAll(u model.Order) error {
 	}
 
 	if u.Data == nil {
-		x, ok := ubs[u.ID]
+		x, ok := update(u.ID)
 		if !ok {
 			return ErrUpdateNil
 		}
@@ -181,6 +354,7 @@ func (g *Game) UpdateAll(u model.Order) error {
 		}
 	}
 
+	u.Game = g.id
 	body, err := json.Marshal(u)
 	if err != nil {
 		return err
@@ -200,6 +374,36 @@ func (g *Game) UpdateAll(u model.Order) error {
 	return nil
 }
 
+// ID returns the game's ID, which could be used to look it up via Get.
+func (g *Game) ID() string { return g.id }
+
+// Player returns the client of player one or two, or nil if the game is closed.
+func (g *Game) Player(p1 bool) *Client {
+	g.mtx.RLock()
+	defer g.mtx.RUnlock()
+	return g.cs[p1]
+}
+
+// Done returns true once the game has ended.
+func (g *Game) Done() bool {
+	g.mtx.RLock()
+	defer g.mtx.RUnlock()
+	return g.done
+}
+
+// Reason returns why the game ended, one of model.Done*. It's zero while the game is in progress.
+func (g *Game) Reason() uint8 {
+	g.mtx.RLock()
+	defer g.mtx.RUnlock()
+	return g.reason
+}
+
+// Correspondence returns true whenever the game has days per move, rather than a live time control.
+func (g *Game) Correspondence() bool { return g.opts.Days > 0 }
+
+// Options returns the game's options
+func (g *Game) Options() model.GameOptions { return g.opts }
+
 // Board returns the actual board.
 func (g *Game) Board() *board.Board { return g.brd }
 
@@ -221,17 +425,20 @@ func (g *Game) AddSpectator(cl *Client) error {
 	cl.mtx.Unlock()
 
 	go func() {
-		body, _ := json.Marshal(model.GameOrder{Brd: g.brd})
-		body, _ = json.Marshal(model.Order{ID: model.OrGame, Data: body})
+		body, _ := json.Marshal(model.GameOrder{ID: g.id, Brd: g.brd, Opening: g.Opening()})
+		body, _ = json.Marshal(model.Order{ID: model.OrGame, Data: body, Game: g.id})
 
 		cl.W.Write(body)
 
 		body, _ = json.Marshal(model.TurnOrder{
-			P1: g.turn,
+			P1:      g.turn,
+			Clock:   g.Clock(),
+			Opening: g.Opening(),
 		})
 		body, _ = json.Marshal(model.Order{
 			ID:   model.OrTurn,
 			Data: body,
+			Game: g.id,
 		})
 
 		cl.W.Write(body)
@@ -255,12 +462,31 @@ func (g *Game) RmSpectator(cl *Client) error {
 
 // Close closes the game, and cleans up any data assigned to the clients or the game struct. It does not send a message to clients indicating that the game is closed
 func (g *Game) close() {
+	g.mtx.Lock()
+	if g.closed {
+		g.mtx.Unlock()
+		return
+	}
+	g.closed = true
+	reason := g.reason
+	g.mtx.Unlock()
+
+	g.notify(func(o Observer) {
+		o.OnEnd(g, EndEvent{Reason: reason})
+	})
+
 	defer g.mtx.Unlock()
 	g.mtx.Lock()
 	go func() {
 		close(g.listenDone)
 	}()
 
+	unregister(g)
+	g.stopClock()
+	if g.idle != nil {
+		g.idle.Stop()
+	}
+
 	do := func(cl *Client) {
 		if cl.g != nil {
 			cl.mtx.Lock()
@@ -254,3 +254,29 @@ func TestGameDone(t *testing.T) {
 	t.Logf("\n%s", gGame.brd.String())
 
 }
+
+func TestNewGameFEN(t *testing.T) {
+	_, err := NewGameWithOptions(&Client{W: specW}, &Client{W: specW}, model.GameOptions{FEN: "8/8/8"})
+	if err != board.ErrInvalidFEN {
+		t.Fatalf("NewGame: want: %v - have: %v", board.ErrInvalidFEN, err)
+	}
+
+	// black to move, only white could castle
+	opts := model.GameOptions{FEN: "rnbqkbnr/pppp1ppp/8/4p3/4P3/8/PPPP1PPP/RNBQKBNR b KQ - 0 2"}
+	g, _, c2, _, _ := newDrainedGame(t, opts)
+
+	if !g.IsTurn(c2) {
+		t.Fatalf("starting position does not have black to move")
+	}
+	if g.canCastle[false] || !g.canCastle[true] {
+		t.Fatalf("starting position does not have the castling rights")
+	}
+
+	_, pec, err := g.brd.Get(board.Point{X: 4, Y: 3})
+	if err != nil || pec.Kind != board.Pawn || pec.P1 {
+		t.Fatalf("starting position is not used, e5 does not have a black pawn")
+	}
+	if g.Opening() != nil {
+		t.Fatalf("custom starting position is classified")
+	}
+}
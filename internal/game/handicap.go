@@ -0,0 +1,68 @@
+package game
+
+import (
+	"github.com/toms1441/chess-server/internal/board"
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+// handicap.go contains odds games, where the stronger player starts with less material or less time.
+
+// oddsPieces are the ids removed from each player for each odds.
+var oddsPieces = map[string]map[bool]int8{
+	model.OddsKnight: {
+		true:  25,
+		false: 1,
+	},
+	model.OddsRook: {
+		true:  24,
+		false: 0,
+	},
+	model.OddsQueen: {
+		true:  27,
+		false: 3,
+	},
+	model.OddsPawnAndMove: {
+		true:  21,
+		false: 13,
+	},
+}
+
+// ValidHandicap returns ErrHandicapInvalid whenever the odds are unknown, or the extra time is negative.
+func ValidHandicap(h *model.Handicap) error {
+	if h == nil {
+		return nil
+	}
+
+	if _, ok := oddsPieces[h.Odds]; !ok && len(h.Odds) > 0 {
+		return ErrHandicapInvalid
+	}
+
+	if h.Time < 0 {
+		return ErrHandicapInvalid
+	}
+
+	return nil
+}
+
+// applyHandicap removes the material given by the handicap from the position, and gives the first move to the opponent with pawn and move.
+func applyHandicap(pos *board.Position, h *model.Handicap) error {
+	err := ValidHandicap(h)
+	if err != nil || h == nil || len(h.Odds) == 0 {
+		return err
+	}
+
+	id := oddsPieces[h.Odds][h.P1]
+	pec, err := pos.Brd.GetByIndex(id)
+	if err != nil || !pec.Valid() {
+		// the piece is not on the board, such as with a custom starting position
+		return ErrHandicapInvalid
+	}
+
+	pos.Brd.Set(id, board.Point{X: -1, Y: -1})
+
+	if h.Odds == model.OddsPawnAndMove {
+		pos.P1 = !h.P1
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+func TestGameHandicap(t *testing.T) {
+	_, err := NewGameWithOptions(&Client{W: specW}, &Client{W: specW}, model.GameOptions{
+		Handicap: &model.Handicap{Odds: "king"},
+	})
+	if err != ErrHandicapInvalid {
+		t.Fatalf("NewGame: want: %v - have: %v", ErrHandicapInvalid, err)
+	}
+
+	// white gives queen odds, and black gets 30 extra seconds
+	opts := model.GameOptions{
+		TimeControl: model.TimeControl{Base: 60},
+		Handicap:    &model.Handicap{Odds: model.OddsQueen, Time: 30, P1: true},
+	}
+	g, c1, _, _, _ := newDrainedGame(t, opts)
+
+	pec, _ := g.brd.GetByIndex(27)
+	if pec.Valid() {
+		t.Fatalf("queen odds does not remove white's queen")
+	}
+	if !g.IsTurn(c1) {
+		t.Fatalf("white does not move first")
+	}
+
+	clock := g.Clock()
+	if clock.P2 < 89000 || clock.P1 > 60000 {
+		t.Fatalf("extra time is not given to black: %+v", clock)
+	}
+}
+
+func TestGameHandicapPawnAndMove(t *testing.T) {
+	opts := model.GameOptions{
+		Handicap: &model.Handicap{Odds: model.OddsPawnAndMove, P1: true},
+	}
+	g, _, c2, _, _ := newDrainedGame(t, opts)
+
+	pec, _ := g.brd.GetByIndex(21)
+	if pec.Valid() {
+		t.Fatalf("pawn and move does not remove white's f pawn")
+	}
+	if !g.IsTurn(c2) {
+		t.Fatalf("pawn and move does not give black the first move")
+	}
+}
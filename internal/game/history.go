@@ -0,0 +1,106 @@
+package game
+
+import (
+	"github.com/toms1441/chess-server/internal/board"
+	"github.com/toms1441/chess-server/internal/eco"
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+// history.go contains the game's history of moves, and the opening that was classified from them.
+
+// standardFEN is the standard starting position, the only one that openings are classified from.
+var standardFEN = board.Position{
+	Brd: board.NewBoard(),
+	P1:  true,
+	Castling: map[bool]bool{
+		true:  true,
+		false: true,
+	},
+}.FEN()
+
+var promotionNotation = map[uint8]string{
+	board.Queen:  "q",
+	board.Rook:   "r",
+	board.Bishop: "b",
+	board.Knight: "n",
+}
+
+// record appends a move to the game's history, and re-classifies the opening.
+func (g *Game) record(h model.History) {
+	if len(h.Notation) == 0 {
+		h.Notation = h.Src.Notation() + h.Dst.Notation()
+	}
+
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	g.history = append(g.history, h)
+	g.classify()
+}
+
+// recordPromotion sets the promotion kind of the last move in the history.
+func (g *Game) recordPromotion(id int8, kind uint8) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	last := len(g.history) - 1
+	if last < 0 || g.history[last].ID != id {
+		return
+	}
+
+	g.history[last].Kind = kind
+	g.history[last].Notation += promotionNotation[kind]
+}
+
+// recordCheck sets the check flag of the last move in the history.
+func (g *Game) recordCheck() {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	last := len(g.history) - 1
+	if last >= 0 {
+		g.history[last].Check = true
+	}
+}
+
+// classify sets the opening from the history. It should be called with g.mtx locked.
+// Games that don't start from the standard position, such as odds games, do not have an opening.
+func (g *Game) classify() {
+	if g.start != standardFEN {
+		g.opening = nil
+		return
+	}
+
+	moves := make([]string, len(g.history))
+	for k, v := range g.history {
+		moves[k] = v.Notation
+	}
+
+	op, ok := eco.Classify(moves)
+	if ok {
+		g.opening = &op
+	} else {
+		g.opening = nil
+	}
+}
+
+// History returns a copy of the game's history.
+func (g *Game) History() []model.History {
+	g.mtx.RLock()
+	defer g.mtx.RUnlock()
+
+	return append([]model.History{}, g.history...)
+}
+
+// Opening returns the opening played in the game, or nil if it's unknown.
+func (g *Game) Opening() *model.Opening {
+	g.mtx.RLock()
+	defer g.mtx.RUnlock()
+
+	if g.opening == nil {
+		return nil
+	}
+
+	op := *g.opening
+	return &op
+}
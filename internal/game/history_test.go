@@ -0,0 +1,96 @@
+package game
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/toms1441/chess-server/internal/board"
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+func TestGameHistory(t *testing.T) {
+	_, w1 := io.Pipe()
+	_, w2 := io.Pipe()
+
+	g, err := NewGame(&Client{W: w1}, &Client{W: w2})
+	if err != nil {
+		t.Fatalf("NewGame: %s", err.Error())
+	}
+
+	if g.Opening() != nil {
+		t.Fatalf("opening should be nil before any move")
+	}
+
+	moves := []struct {
+		id  int8
+		dst board.Point
+	}{
+		{20, board.Point{X: 4, Y: 4}}, // e2e4
+		{12, board.Point{X: 4, Y: 3}}, // e7e5
+		{30, board.Point{X: 5, Y: 5}}, // g1f3
+	}
+
+	for _, v := range moves {
+		if !g.brd.Move(v.id, v.dst) {
+			t.Fatalf("brd.Move: cannot move %d to %s", v.id, v.dst)
+		}
+	}
+
+	history := g.History()
+	if len(history) != len(moves) {
+		t.Fatalf("len(history): want: %d - have: %d", len(moves), len(history))
+	}
+
+	if history[0].Notation != "e2e4" || history[2].Notation != "g1f3" {
+		t.Fatalf("invalid notation: %v", history)
+	}
+
+	op := g.Opening()
+	if op == nil || op.ECO != "C40" {
+		t.Fatalf("invalid opening: %v", op)
+	}
+}
+
+func TestGameOpeningTurn(t *testing.T) {
+	g, c1, c2, ch1, ch2 := newDrainedGame(t, model.GameOptions{})
+
+	doMoveOrder(t, c1, 20, board.Point{X: 4, Y: 4})
+	doMoveOrder(t, c2, 12, board.Point{X: 4, Y: 3})
+	doMoveOrder(t, c1, 30, board.Point{X: 5, Y: 5})
+
+	// turn updates have the opening as it changes with every move
+	turn := model.TurnOrder{}
+	for turn.Opening == nil || turn.Opening.ECO != "C40" {
+		o := waitFor(t, ch2, model.OrTurn)
+		turn = model.TurnOrder{}
+		json.Unmarshal(o.Data, &turn)
+	}
+
+	// the opening is no longer known after taking back every move
+	for i := 0; i < 3; i++ {
+		err := g.Takeback()
+		if err != nil {
+			t.Fatalf("g.Takeback: %s", err.Error())
+		}
+	}
+
+	for turn.Opening != nil {
+		o := waitFor(t, ch1, model.OrTurn)
+		turn = model.TurnOrder{}
+		json.Unmarshal(o.Data, &turn)
+	}
+}
+
+func TestGameOpeningHandicap(t *testing.T) {
+	g, c1, c2, _, _ := newDrainedGame(t, model.GameOptions{
+		Handicap: &model.Handicap{Odds: model.OddsKnight, P1: false},
+	})
+
+	doMoveOrder(t, c1, 20, board.Point{X: 4, Y: 4})
+	doMoveOrder(t, c2, 12, board.Point{X: 4, Y: 3})
+
+	if g.Opening() != nil {
+		t.Fatalf("odds game is classified: %v", g.Opening())
+	}
+}
@@ -0,0 +1,106 @@
+package game
+
+import (
+	"sync"
+
+	"github.com/toms1441/chess-server/internal/board"
+)
+
+// observer.go contains the game's lifecycle hooks, used by integrations such as storage, ratings and metrics.
+
+// MoveEvent is sent to observers whenever a piece moves, castling excluded.
+type MoveEvent struct {
+	ID    int8
+	Piece board.Piece
+	Src   board.Point
+	Dst   board.Point
+}
+
+// CheckEvent is sent to observers whenever a king is in check.
+type CheckEvent struct {
+	// P1 is the player in check
+	P1   bool
+	King board.Point
+	// Pieces are the ids of the pieces checking the king
+	Pieces []int8
+}
+
+// PromotionEvent is sent to observers whenever a pawn gets promoted.
+type PromotionEvent struct {
+	ID   int8
+	Kind uint8
+	P1   bool
+}
+
+// CastleEvent is sent to observers whenever a player castles.
+type CastleEvent struct {
+	P1   bool
+	King int8
+	Rook int8
+}
+
+// EndEvent is sent to observers whenever the game ends.
+type EndEvent struct {
+	// Reason is one of model.Done*
+	Reason uint8
+}
+
+// Observer gets notified of a game's lifecycle. Observers are called synchronously, so they should not block, nor call the game's commands.
+type Observer interface {
+	OnStart(g *Game)
+	OnMove(g *Game, ev MoveEvent)
+	OnCheck(g *Game, ev CheckEvent)
+	OnPromotion(g *Game, ev PromotionEvent)
+	OnCastle(g *Game, ev CastleEvent)
+	OnEnd(g *Game, ev EndEvent)
+}
+
+// NopObserver implements every Observer method without doing anything. Embed it to only implement the needed methods.
+type NopObserver struct{}
+
+func (NopObserver) OnStart(g *Game)                        {}
+func (NopObserver) OnMove(g *Game, ev MoveEvent)           {}
+func (NopObserver) OnCheck(g *Game, ev CheckEvent)         {}
+func (NopObserver) OnPromotion(g *Game, ev PromotionEvent) {}
+func (NopObserver) OnCastle(g *Game, ev CastleEvent)       {}
+func (NopObserver) OnEnd(g *Game, ev EndEvent)             {}
+
+var observers = []Observer{}
+var observermtx sync.RWMutex
+
+// Observe registers an observer for every game.
+func Observe(o Observer) {
+	observermtx.Lock()
+	observers = append(observers, o)
+	observermtx.Unlock()
+}
+
+// Observe registers an observer for the game only.
+func (g *Game) Observe(o Observer) {
+	g.mtx.Lock()
+	g.observers = append(g.observers, o)
+	g.mtx.Unlock()
+}
+
+// notify calls fn for the global observers, then for the game's observers. It should be called with g.mtx unlocked.
+func (g *Game) notify(fn func(o Observer)) {
+	observermtx.RLock()
+	obs := append([]Observer{}, observers...)
+	observermtx.RUnlock()
+
+	g.mtx.RLock()
+	obs = append(obs, g.observers...)
+	g.mtx.RUnlock()
+
+	for _, o := range obs {
+		fn(o)
+	}
+}
+
+// end sets the reason the game ended, which is sent to observers once the game closes.
+func (g *Game) end(reason uint8) {
+	g.mtx.Lock()
+	g.done = true
+	g.reason = reason
+	g.mtx.Unlock()
+}
@@ -0,0 +1,34 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/toms1441/chess-server/internal/board"
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+type testObserver struct {
+	NopObserver
+	moves []MoveEvent
+	end   *EndEvent
+}
+
+func (to *testObserver) OnMove(g *Game, ev MoveEvent) { to.moves = append(to.moves, ev) }
+func (to *testObserver) OnEnd(g *Game, ev EndEvent)   { to.end = &ev }
+
+func TestGameObserver(t *testing.T) {
+	g, c1, _, _, _ := newDrainedGame(t, model.GameOptions{})
+
+	to := &testObserver{}
+	g.Observe(to)
+
+	doMoveOrder(t, c1, 20, board.Point{X: 4, Y: 4})
+	if len(to.moves) != 1 || to.moves[0].ID != 20 || to.moves[0].Dst != (board.Point{X: 4, Y: 4}) {
+		t.Fatalf("observer does not receive the move: %+v", to.moves)
+	}
+
+	c1.LeaveGame()
+	if to.end == nil || to.end.Reason != model.DoneWhiteForfeit {
+		t.Fatalf("observer does not receive the end reason: %+v", to.end)
+	}
+}
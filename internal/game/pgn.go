@@ -0,0 +1,121 @@
+package game
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/toms1441/chess-server/internal/model"
+	"github.com/toms1441/chess-server/internal/rating"
+)
+
+// pgn.go contains the export of games in PGN, so that they could be analysed in other tools.
+
+// pgnResults are the PGN results of player one's score.
+var pgnResults = map[float64]string{
+	1:   "1-0",
+	0:   "0-1",
+	0.5: "1/2-1/2",
+}
+
+// handicapNames are the readable names of each odds.
+var handicapNames = map[string]string{
+	model.OddsKnight:      "knight odds",
+	model.OddsRook:        "rook odds",
+	model.OddsQueen:       "queen odds",
+	model.OddsPawnAndMove: "pawn and move",
+}
+
+// PGN returns the game in PGN. Games with a custom starting position or a handicap have the SetUp and FEN tags, and the handicap is described in a Handicap tag.
+// Moves are written in the long algebraic notation of the history, i.e e2e4 instead of e4. The players' names are only known until the game is closed, so call it from OnEnd at the latest.
+func (g *Game) PGN() string {
+	g.mtx.RLock()
+	defer g.mtx.RUnlock()
+
+	result := "*"
+	if score, ok := rating.Score(g.reason); ok {
+		result = pgnResults[score]
+	}
+
+	tags := [][2]string{
+		{"Event", "?"},
+		{"Site", "?"},
+		{"Date", "????.??.??"},
+		{"Round", "-"},
+		{"White", "?"},
+		{"Black", "?"},
+		{"Result", result},
+	}
+
+	if cl := g.cs[true]; cl != nil {
+		tags[4][1] = cl.Profile.Username
+	}
+	if cl := g.cs[false]; cl != nil {
+		tags[5][1] = cl.Profile.Username
+	}
+
+	if g.opening != nil {
+		tags = append(tags, [2]string{"ECO", g.opening.ECO}, [2]string{"Opening", g.opening.Name})
+	}
+
+	tc := g.opts.TimeControl
+	if g.opts.Days > 0 {
+		tags = append(tags, [2]string{"TimeControl", fmt.Sprintf("1/%d", g.opts.Days*24*60*60)})
+	} else if tc.Base > 0 {
+		tags = append(tags, [2]string{"TimeControl", fmt.Sprintf("%d+%d", tc.Base, tc.Increment)})
+	}
+
+	h := g.opts.Handicap
+	if len(g.opts.FEN) > 0 || (h != nil && len(h.Odds) > 0) {
+		tags = append(tags, [2]string{"SetUp", "1"}, [2]string{"FEN", g.start})
+	}
+
+	if h != nil {
+		giver, taker := "White", "Black"
+		if !h.P1 {
+			giver, taker = taker, giver
+		}
+
+		desc := []string{}
+		if len(h.Odds) > 0 {
+			desc = append(desc, giver+" gives "+handicapNames[h.Odds])
+		}
+		if h.Time > 0 && g.clock != nil {
+			desc = append(desc, fmt.Sprintf("%s gets %d extra seconds", taker, h.Time))
+		}
+
+		if len(desc) > 0 {
+			tags = append(tags, [2]string{"Handicap", strings.Join(desc, ", ")})
+		}
+	}
+
+	str := ""
+	for _, v := range tags {
+		str += fmt.Sprintf("[%s %q]\n", v[0], v[1])
+	}
+	str += "\n"
+
+	// the first move is black's whenever the game starts with black to move
+	ply := 0
+	if strings.Contains(g.start, " b ") {
+		ply = 1
+	}
+
+	moves := []string{}
+	for k, v := range g.history {
+		n := (ply+k)/2 + 1
+		if (ply+k)%2 == 0 {
+			moves = append(moves, fmt.Sprintf("%d.", n))
+		} else if k == 0 {
+			moves = append(moves, fmt.Sprintf("%d...", n))
+		}
+
+		san := v.Notation
+		if v.Check {
+			san += "+"
+		}
+		moves = append(moves, san)
+	}
+	moves = append(moves, result)
+
+	return str + strings.Join(moves, " ") + "\n"
+}
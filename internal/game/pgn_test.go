@@ -0,0 +1,42 @@
+package game
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/toms1441/chess-server/internal/board"
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+func TestGamePGN(t *testing.T) {
+	g, c1, c2, _, _ := newDrainedGame(t, model.GameOptions{
+		TimeControl: model.TimeControl{Base: 300, Increment: 3},
+		Handicap:    &model.Handicap{Odds: model.OddsPawnAndMove, Time: 30, P1: true},
+	})
+
+	doMoveOrder(t, c2, 12, board.Point{X: 4, Y: 3})
+	doMoveOrder(t, c1, 20, board.Point{X: 4, Y: 4})
+
+	pgn := g.PGN()
+	for _, tag := range []string{
+		`[Result "*"]`,
+		`[TimeControl "300+3"]`,
+		`[SetUp "1"]`,
+		`[FEN "rnbqkbnr/pppppppp/8/8/8/8/PPPPP1PP/RNBQKBNR b KQkq - 0 1"]`,
+		`[Handicap "White gives pawn and move, Black gets 30 extra seconds"]`,
+	} {
+		if !strings.Contains(pgn, tag) {
+			t.Fatalf("PGN does not contain %s:\n%s", tag, pgn)
+		}
+	}
+
+	if !strings.HasSuffix(pgn, "\n1... e7e5 2. e2e4 *\n") {
+		t.Fatalf("unexpected moves:\n%s", pgn)
+	}
+
+	// even games from the starting position don't have a FEN
+	g, _, _, _, _ = newDrainedGame(t, model.GameOptions{})
+	if strings.Contains(g.PGN(), "FEN") {
+		t.Fatalf("even game has a FEN:\n%s", g.PGN())
+	}
+}
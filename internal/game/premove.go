@@ -0,0 +1,108 @@
+package game
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/toms1441/chess-server/internal/board"
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+// premove.go contains moves queued while it's the opponent's turn, which get played right after the opponent's move.
+
+type premove struct {
+	c *Client
+	o model.Order
+	s model.MoveOrder
+}
+
+// queuePremove stores the move, replacing any previous premove of the client. The move is validated once it's the client's turn.
+func (g *Game) queuePremove(c *Client, o model.Order) error {
+	s := model.MoveOrder{}
+
+	err := json.Unmarshal(o.Data, &s)
+	// unmarshal the order
+	if err != nil {
+		return fmt.Errorf("json.Unmarshal: %w", err)
+	}
+
+	if !board.BelongsTo(s.ID, c.p1) {
+		return ErrIllegalMove
+	}
+
+	g.mtx.Lock()
+	g.premove = &premove{
+		c: c,
+		o: o,
+		s: s,
+	}
+	g.mtx.Unlock()
+
+	return nil
+}
+
+// firePremove plays the premove of p1 if it's still legal, otherwise it gets cancelled. The owner is notified after the move is played, whether it fired or got cancelled.
+// It's called from the opponent's move, which holds g.cmd, so the owner's own commands can't run until the premove is played.
+func (g *Game) firePremove(p1 bool) {
+	g.mtx.Lock()
+	pm := g.premove
+	if pm == nil || pm.c.p1 != p1 || g.done {
+		g.mtx.Unlock()
+		return
+	}
+	g.premove = nil
+	g.mtx.Unlock()
+
+	if pm.c.inPromotion() {
+		pm.cancel(g)
+		return
+	}
+
+	pec, err := g.brd.GetByIndex(pm.s.ID)
+	if err != nil || !pec.Valid() || pec.P1 != p1 {
+		pm.cancel(g)
+		return
+	}
+
+	ps, err := g.brd.Possib(pm.s.ID)
+	if err != nil || !ps.In(pm.s.Dst) {
+		pm.cancel(g)
+		return
+	}
+
+	// the move could still fail, such as when it leaves the king in check
+	cb, _ := command(model.OrMove)
+	err = cb(pm.c, pm.o)
+	if err != nil {
+		pm.cancel(g)
+		return
+	}
+
+	body, err := json.Marshal(model.PremoveOrder{
+		MoveOrder: pm.s,
+		Fired:     true,
+	})
+	if err != nil {
+		return
+	}
+
+	g.Update(pm.c, model.Order{
+		ID:   model.OrPremove,
+		Data: body,
+	})
+}
+
+// cancel notifies the owner that their premove got cancelled.
+func (pm *premove) cancel(g *Game) {
+	body, err := json.Marshal(model.PremoveOrder{
+		MoveOrder: pm.s,
+	})
+	if err != nil {
+		return
+	}
+
+	g.Update(pm.c, model.Order{
+		ID:   model.OrPremove,
+		Data: body,
+	})
+}
@@ -0,0 +1,116 @@
+package game
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/toms1441/chess-server/internal/board"
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+func TestCommandPremove(t *testing.T) {
+	g, c1, c2, _, ch2 := newDrainedGame(t, model.GameOptions{})
+
+	// e7e5 while it's white's turn
+	doMoveOrder(t, c2, 12, board.Point{X: 4, Y: 3})
+	doMoveOrder(t, c1, 20, board.Point{X: 4, Y: 4})
+
+	o := waitFor(t, ch2, model.OrPremove)
+	pm := model.PremoveOrder{}
+	json.Unmarshal(o.Data, &pm)
+	if !pm.Fired {
+		t.Fatalf("premove does not fire")
+	}
+
+	pec, _ := g.brd.GetByIndex(12)
+	if pec.Pos != (board.Point{X: 4, Y: 3}) {
+		t.Fatalf("premove is not played, pawn is at %s", pec.Pos.Notation())
+	}
+	if !g.IsTurn(c1) {
+		t.Fatalf("premove does not switch turns")
+	}
+
+	// e5e4 is blocked after e2e4
+	doMoveOrder(t, c2, 12, board.Point{X: 4, Y: 4})
+	doMoveOrder(t, c1, 21, board.Point{X: 5, Y: 5})
+
+	o = waitFor(t, ch2, model.OrPremove)
+	pm = model.PremoveOrder{}
+	json.Unmarshal(o.Data, &pm)
+	if pm.Fired {
+		t.Fatalf("illegal premove fires")
+	}
+	if !g.IsTurn(c2) {
+		t.Fatalf("cancelled premove switches turns")
+	}
+}
+
+func TestCommandPremoveOwnPiece(t *testing.T) {
+	_, _, c2, _, _ := newDrainedGame(t, model.GameOptions{})
+
+	body, _ := json.Marshal(model.MoveOrder{ID: 20, Dst: board.Point{X: 4, Y: 4}})
+	err := c2.Do(model.Order{ID: model.OrMove, Data: body})
+	if err != ErrIllegalMove {
+		t.Fatalf("cl.Do: premove with the opponent's piece, want: %v - have: %v", ErrIllegalMove, err)
+	}
+}
+
+func TestCommandPremoveFiredAfterMove(t *testing.T) {
+	_, c1, c2, _, ch2 := newDrainedGame(t, model.GameOptions{})
+
+	doMoveOrder(t, c2, 12, board.Point{X: 4, Y: 3})
+	doMoveOrder(t, c1, 20, board.Point{X: 4, Y: 4})
+
+	// the premove is only reported as fired once it's played
+	played := false
+	for {
+		select {
+		case <-time.After(time.Millisecond * 100):
+			t.Fatalf("timeout while waiting for the premove update")
+		case o := <-ch2:
+			switch o.ID {
+			case model.OrMove:
+				mo := model.MoveOrder{}
+				json.Unmarshal(o.Data, &mo)
+				if mo.ID == 12 {
+					played = true
+				}
+			case model.OrPremove:
+				if !played {
+					t.Fatalf("premove is reported before it's played")
+				}
+				return
+			}
+		}
+	}
+}
+
+func TestCommandPremoveSerialized(t *testing.T) {
+	g, c1, c2, _, _ := newDrainedGame(t, model.GameOptions{})
+
+	doMoveOrder(t, c2, 12, board.Point{X: 4, Y: 3})
+
+	// an in-flight command of white, such as the move that fires black's premove
+	g.cmd.Lock()
+
+	done := make(chan error)
+	go func() {
+		done <- c2.Do(model.Order{ID: model.OrTakeback})
+	}()
+
+	select {
+	case <-done:
+		t.Fatalf("black's command runs during white's command")
+	case <-time.After(time.Millisecond * 10):
+	}
+
+	g.cmd.Unlock()
+	<-done
+
+	doMoveOrder(t, c1, 20, board.Point{X: 4, Y: 4})
+	pec, _ := g.brd.GetByIndex(12)
+	if pec.Pos != (board.Point{X: 4, Y: 3}) {
+		t.Fatalf("premove is not played after waiting for the lock")
+	}
+}
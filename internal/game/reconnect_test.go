@@ -0,0 +1,64 @@
+package game
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/toms1441/chess-server/internal/board"
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+func TestClientReconnect(t *testing.T) {
+	g, c1, c2, ch1, _ := newDrainedGame(t, model.GameOptions{})
+
+	c2.Disconnect()
+	if c2.Connected() {
+		t.Fatalf("client is connected after Disconnect")
+	}
+
+	o := waitFor(t, ch1, model.OrDisconnected)
+	dis := model.DisconnectedOrder{}
+	json.Unmarshal(o.Data, &dis)
+	if dis.P1 != c2.P1() {
+		t.Fatalf("disconnected update has the wrong player")
+	}
+
+	// the game goes on without blocking on the disconnected client
+	doMoveOrder(t, c1, 20, board.Point{X: 4, Y: 4})
+
+	rd, wr := io.Pipe()
+	ch2 := drain(rd)
+
+	pro := model.Profile{ID: "a", Platform: "b"}
+	err := c2.Reconnect(wr, &pro)
+	if err != nil {
+		t.Fatalf("cl.Reconnect: %s", err.Error())
+	}
+
+	o = waitFor(t, ch2, model.OrGame)
+	gme := model.GameOrder{}
+	json.Unmarshal(o.Data, &gme)
+	if gme.P1 == nil || *gme.P1 != c2.P1() || gme.Profile == nil || *gme.Profile != pro {
+		t.Fatalf("game update does not have the player or the opponent's profile")
+	}
+
+	o = waitFor(t, ch2, model.OrTurn)
+	turn := model.TurnOrder{}
+	json.Unmarshal(o.Data, &turn)
+	if turn.P1 != c2.P1() {
+		t.Fatalf("turn update does not have the current turn")
+	}
+
+	o = waitFor(t, ch2, model.OrHistory)
+	his := model.HistoryOrder{}
+	json.Unmarshal(o.Data, &his)
+	if len(his.History) != len(g.History()) {
+		t.Fatalf("history update does not have every move, want: %d - have: %d", len(g.History()), len(his.History))
+	}
+
+	waitFor(t, ch1, model.OrReconnected)
+	if !c2.Connected() {
+		t.Fatalf("client is not connected after Reconnect")
+	}
+}
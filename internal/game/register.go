@@ -0,0 +1,111 @@
+package game
+
+import (
+	"sync"
+
+	"github.com/toms1441/chess-server/internal/board"
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+// register.go lets other packages add their own orders, without touching cbs or ubs.
+
+// ordermtx protects cbs and ubs from registrations while they're being read.
+var ordermtx sync.RWMutex
+
+// Context is given to handlers registered via RegisterCommand.
+//
+// The handler runs with the client locked, so it must not call Client.Do or Client.Game. Instead, use Game to read the game's state or send updates, i.e Game.Board, Game.IsTurn, Game.Update and Game.UpdateAll.
+type Context struct {
+	Client *Client
+	Game   *Game
+	// P1 is the client's player number. It's false for spectators.
+	P1 bool
+	// Player is false for spectators.
+	Player bool
+}
+
+// Opponent returns the client's opponent, or nil for spectators.
+func (ctx Context) Opponent() *Client {
+	if !ctx.Player {
+		return nil
+	}
+
+	return ctx.Game.Player(board.GetInversePlayer(ctx.P1))
+}
+
+// Handler is a command handler registered via RegisterCommand.
+type Handler func(ctx Context, o model.Order) error
+
+// RegisterCommand adds a command, which clients could send via Client.Do.
+// id must start from model.OrCustom, and not be registered already.
+func RegisterCommand(id uint8, h Handler) error {
+	if id < model.OrCustom {
+		return ErrOrderReserved
+	}
+
+	ordermtx.Lock()
+	defer ordermtx.Unlock()
+
+	if _, ok := cbs[id]; ok {
+		return ErrOrderRegistered
+	}
+
+	cbs[id] = func(c *Client, o model.Order) error {
+		g := c.g
+		return h(Context{
+			Client: c,
+			Game:   g,
+			P1:     c.p1,
+			Player: g.IsPlayer(c),
+		}, o)
+	}
+
+	return nil
+}
+
+// RegisterUpdate adds an update callback, which sets the update's data whenever it's sent without it.
+// id must start from model.OrCustom, and not be registered already.
+func RegisterUpdate(id uint8, cb UpdateCallback) error {
+	if id < model.OrCustom {
+		return ErrOrderReserved
+	}
+
+	ordermtx.Lock()
+	defer ordermtx.Unlock()
+
+	if _, ok := ubs[id]; ok {
+		return ErrOrderRegistered
+	}
+
+	ubs[id] = cb
+	return nil
+}
+
+// unregisterOrder removes the command and update with id, it's used by tests. Built-in orders can't be removed.
+func unregisterOrder(id uint8) {
+	if id < model.OrCustom {
+		return
+	}
+
+	ordermtx.Lock()
+	defer ordermtx.Unlock()
+
+	delete(cbs, id)
+	delete(ubs, id)
+}
+
+func command(id uint8) (CommandCallback, bool) {
+	ordermtx.RLock()
+	defer ordermtx.RUnlock()
+
+	cb, ok := cbs[id]
+	return cb, ok
+}
+
+func update(id uint8) (UpdateCallback, bool) {
+	ordermtx.RLock()
+	defer ordermtx.RUnlock()
+
+	cb, ok := ubs[id]
+	return cb, ok
+}
@@ -0,0 +1,64 @@
+package game
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+func TestRegisterCommand(t *testing.T) {
+	const id = model.OrCustom + 1
+
+	err := RegisterCommand(model.OrMove, nil)
+	if err != ErrOrderReserved {
+		t.Fatalf("RegisterCommand: want: %v - have: %v", ErrOrderReserved, err)
+	}
+
+	t.Cleanup(func() { unregisterOrder(id) })
+
+	// ping sends the opponent a pong update
+	err = RegisterCommand(id, func(ctx Context, o model.Order) error {
+		return ctx.Game.Update(ctx.Opponent(), model.Order{
+			ID:        id,
+			Parameter: "pong",
+		})
+	})
+	if err != nil {
+		t.Fatalf("RegisterCommand: %s", err.Error())
+	}
+
+	err = RegisterCommand(id, nil)
+	if err != ErrOrderRegistered {
+		t.Fatalf("RegisterCommand: want: %v - have: %v", ErrOrderRegistered, err)
+	}
+
+	err = RegisterUpdate(id, func(c *Client, u *model.Order) error {
+		str, ok := u.Parameter.(string)
+		if !ok {
+			return ErrUpdateParameter
+		}
+
+		u.Data, _ = json.Marshal(str)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RegisterUpdate: %s", err.Error())
+	}
+
+	err = RegisterUpdate(id, nil)
+	if err != ErrOrderRegistered {
+		t.Fatalf("RegisterUpdate: want: %v - have: %v", ErrOrderRegistered, err)
+	}
+
+	_, c1, _, _, ch2 := newDrainedGame(t, model.GameOptions{})
+	err = c1.Do(model.Order{ID: id})
+	if err != nil {
+		t.Fatalf("cl.Do: %s", err.Error())
+	}
+
+	o := waitFor(t, ch2, id)
+	if string(o.Data) != `"pong"` {
+		t.Fatalf("custom update data, want: \"pong\" - have: %s", string(o.Data))
+	}
+}
@@ -0,0 +1,72 @@
+package game
+
+import (
+	"sync"
+
+	"github.com/kjk/betterguid"
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+// registry.go contains every game in progress, indexed by their ID.
+
+var games = map[string]*Game{}
+var gamemtx sync.RWMutex
+
+// register assigns an ID to the game if it doesn't have one, and adds it to the registry.
+func register(g *Game) {
+	if len(g.id) == 0 {
+		g.id = betterguid.New()
+	}
+
+	gamemtx.Lock()
+	games[g.id] = g
+	gamemtx.Unlock()
+}
+
+func unregister(g *Game) {
+	gamemtx.Lock()
+	delete(games, g.id)
+	gamemtx.Unlock()
+}
+
+// Get returns the game in progress with that ID.
+func Get(id string) (*Game, error) {
+	gamemtx.RLock()
+	defer gamemtx.RUnlock()
+
+	g, ok := games[id]
+	if !ok {
+		return nil, ErrGameNil
+	}
+
+	return g, nil
+}
+
+// Games returns every game in progress.
+func Games() []*Game {
+	gamemtx.RLock()
+	defer gamemtx.RUnlock()
+
+	sl := make([]*Game, 0, len(games))
+	for _, g := range games {
+		sl = append(sl, g)
+	}
+
+	return sl
+}
+
+// ByPlayer returns the games in progress where one of the players has the same ID and platform as pro. Clients need Client.Profile set to be found.
+func ByPlayer(pro model.Profile) []*Game {
+	sl := []*Game{}
+	for _, g := range Games() {
+		for _, p1 := range []bool{true, false} {
+			cl := g.Player(p1)
+			if cl != nil && cl.Profile.ID == pro.ID && cl.Profile.Platform == pro.Platform {
+				sl = append(sl, g)
+				break
+			}
+		}
+	}
+
+	return sl
+}
@@ -0,0 +1,33 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+func TestGameRegistry(t *testing.T) {
+	g, c1, _, _, _ := newDrainedGame(t, model.GameOptions{})
+
+	if len(g.ID()) == 0 {
+		t.Fatalf("game does not have an id")
+	}
+
+	x, err := Get(g.ID())
+	if err != nil || x != g {
+		t.Fatalf("Get: game is not registered")
+	}
+
+	c1.Profile = model.Profile{ID: "registry", Platform: "test"}
+	gs := ByPlayer(model.Profile{ID: "registry", Platform: "test"})
+	if len(gs) != 1 || gs[0] != g {
+		t.Fatalf("ByPlayer: want: 1 game - have: %d", len(gs))
+	}
+
+	c1.LeaveGame()
+
+	_, err = Get(g.ID())
+	if err != ErrGameNil {
+		t.Fatalf("Get: closed game, want: %v - have: %v", ErrGameNil, err)
+	}
+}
@@ -0,0 +1,104 @@
+package game
+
+import (
+	"time"
+
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+// snapshot.go saves and restores games in progress, so that they survive a restart.
+
+// Snapshot returns the entire state of the game.
+func (g *Game) Snapshot() model.Snapshot {
+	clock := g.Clock()
+	history := g.History()
+	chat := g.Messages()
+
+	g.mtx.RLock()
+	defer g.mtx.RUnlock()
+
+	s := model.Snapshot{
+		ID:   g.id,
+		Brd:  g.brd.Copy(),
+		Turn: g.turn,
+		Castling: model.Castling{
+			P1: g.canCastle[true],
+			P2: g.canCastle[false],
+		},
+		Clock:   clock,
+		History: history,
+		Chat:    chat,
+		Options: g.opts,
+	}
+
+	if cl := g.cs[true]; cl != nil {
+		s.P1 = cl.Profile
+	}
+	if cl := g.cs[false]; cl != nil {
+		s.P2 = cl.Profile
+	}
+
+	return s
+}
+
+// Restore creates a game from a snapshot. Both players are disconnected until they call Client.Reconnect, and the clock starts once the side to move reconnects, unless it's a correspondence game.
+func Restore(s model.Snapshot) (*Game, error) {
+	if s.Brd == nil || len(s.ID) == 0 {
+		return nil, ErrSnapshotInvalid
+	}
+
+	if _, err := Get(s.ID); err == nil {
+		return nil, ErrGameIsNotNil
+	}
+
+	cl1 := &Client{W: discard{}, Profile: s.P1, disconnected: true}
+	cl2 := &Client{W: discard{}, Profile: s.P2, disconnected: true}
+
+	g, err := newGame(cl1, cl2, s.Options, s.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	g.mtx.Lock()
+	g.restore(s)
+	g.away[true], g.away[false] = true, true
+	g.watchTurn()
+	g.mtx.Unlock()
+
+	// correspondence clocks run whether or not the players are connected
+	if g.Correspondence() {
+		g.resumeClock()
+	}
+
+	return g, nil
+}
+
+// restore sets the game's state to the snapshot's. It should be called with g.mtx locked.
+func (g *Game) restore(s model.Snapshot) {
+	g.brd.Restore(s.Brd)
+	g.turn = s.Turn
+	g.canCastle[true] = s.Castling.P1
+	g.canCastle[false] = s.Castling.P2
+	g.history = append([]model.History{}, s.History...)
+	g.classify()
+	g.chat = append([]model.ChatOrder{}, s.Chat...)
+	g.started = true
+
+	if g.clock != nil && s.Clock != nil {
+		g.clock.remaining[true] = time.Duration(s.Clock.P1) * time.Millisecond
+		g.clock.remaining[false] = time.Duration(s.Clock.P2) * time.Millisecond
+	}
+}
+
+// resumeClock starts the clock of the side to move whenever it's stopped, such as after a restore.
+func (g *Game) resumeClock() {
+	g.mtx.RLock()
+	cl := g.clock
+	stopped := cl != nil && !cl.running && !g.done
+	turn := g.turn
+	g.mtx.RUnlock()
+
+	if stopped {
+		g.tick(turn, false)
+	}
+}
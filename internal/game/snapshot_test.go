@@ -0,0 +1,52 @@
+package game
+
+import (
+	"testing"
+
+	"github.com/toms1441/chess-server/internal/board"
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+func TestGameSnapshot(t *testing.T) {
+	opts := model.GameOptions{TimeControl: model.TimeControl{Base: 60}}
+	g, c1, c2, _, _ := newDrainedGame(t, opts)
+	c1.Profile = model.Profile{ID: "a", Platform: "test"}
+	c2.Profile = model.Profile{ID: "b", Platform: "test"}
+
+	doMoveOrder(t, c1, 20, board.Point{X: 4, Y: 4})
+
+	s := g.Snapshot()
+	if s.ID != g.ID() || s.P1 != c1.Profile || s.P2 != c2.Profile || s.Clock == nil {
+		t.Fatalf("snapshot is missing the game's state: %+v", s)
+	}
+
+	_, err := Restore(s)
+	if err != ErrGameIsNotNil {
+		t.Fatalf("Restore: game in progress, want: %v - have: %v", ErrGameIsNotNil, err)
+	}
+
+	before := g.brd.String()
+	c1.LeaveGame()
+
+	x, err := Restore(s)
+	if err != nil {
+		t.Fatalf("Restore: %s", err.Error())
+	}
+	defer x.close()
+
+	if x.ID() != s.ID || x.brd.String() != before || x.turn != s.Turn {
+		t.Fatalf("restored game does not have the same state")
+	}
+	if len(x.History()) != 1 || x.Opening() == nil {
+		t.Fatalf("restored game does not have the history")
+	}
+
+	r1 := x.Player(true)
+	if r1.Connected() || r1.Profile != s.P1 {
+		t.Fatalf("restored player is connected, or has the wrong profile")
+	}
+
+	if x.clock.running {
+		t.Fatalf("clock runs before the side to move reconnects")
+	}
+}
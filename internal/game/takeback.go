@@ -0,0 +1,118 @@
+package game
+
+import (
+	"encoding/json"
+
+	"github.com/toms1441/chess-server/internal/board"
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+// takeback.go contains the previous states of the game, which are used to take back moves.
+
+// state is the state of the game before a move.
+type state struct {
+	brd       *board.Board
+	canCastle map[bool]bool
+	turn      bool
+	// history is the length of the history
+	history int
+}
+
+// save returns the current state of the game. It should be pushed via push once the move is done.
+func (g *Game) save() state {
+	g.mtx.RLock()
+	defer g.mtx.RUnlock()
+
+	return state{
+		brd: g.brd.Copy(),
+		canCastle: map[bool]bool{
+			true:  g.canCastle[true],
+			false: g.canCastle[false],
+		},
+		turn:    g.turn,
+		history: len(g.history),
+	}
+}
+
+// push adds the state before the last move, and discards any pending takeback or adjournment since it's about an older move.
+func (g *Game) push(s state) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	g.states = append(g.states, s)
+	g.takeback = nil
+	g.adjourn = nil
+}
+
+// Takeback reverts the last move, including captures, promotions and castling rights. Then it sends the new board and turn to the players and spectators.
+func (g *Game) Takeback() error {
+	return g.revert(1)
+}
+
+// plies returns how many moves have to be reverted to take back the last move of p1. It's two whenever p1 is the side to move, as the opponent moved after them. It should be called with g.mtx locked.
+func (g *Game) plies(p1 bool) int {
+	if g.turn == p1 {
+		return 2
+	}
+
+	return 1
+}
+
+// revert reverts the last n moves, the same way as Takeback.
+func (g *Game) revert(n int) error {
+	g.mtx.Lock()
+	last := len(g.states) - n
+	if last < 0 || n < 1 {
+		g.mtx.Unlock()
+		return ErrTakebackNil
+	}
+
+	s := g.states[last]
+	g.states = g.states[:last]
+
+	g.brd.Restore(s.brd)
+	g.canCastle = s.canCastle
+	g.turn = s.turn
+	g.watchTurn()
+	g.history = g.history[:s.history]
+	g.classify()
+	g.takeback = nil
+	pm := g.premove
+	g.premove = nil
+	g.mtx.Unlock()
+
+	if pm != nil {
+		pm.cancel(g)
+	}
+
+	body, err := json.Marshal(model.BoardOrder{
+		Brd: g.brd,
+	})
+	if err != nil {
+		return err
+	}
+
+	err = g.UpdateAll(model.Order{
+		ID:   model.OrBoard,
+		Data: body,
+	})
+	if err != nil {
+		return err
+	}
+
+	g.tick(s.turn, false)
+
+	body, err = json.Marshal(model.TurnOrder{
+		P1:      s.turn,
+		Clock:   g.Clock(),
+		Opening: g.Opening(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return g.UpdateAll(model.Order{
+		ID:   model.OrTurn,
+		Data: body,
+	})
+}
@@ -0,0 +1,162 @@
+package game
+
+import (
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/toms1441/chess-server/internal/board"
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+// drain reads every update written to rd, so that tests don't have to care about the order of updates.
+func drain(rd *io.PipeReader) chan model.Order {
+	ch := make(chan model.Order, 64)
+
+	go func() {
+		for {
+			body := make([]byte, 4096)
+			n, err := rd.Read(body)
+			if err != nil {
+				close(ch)
+				return
+			}
+
+			o := model.Order{}
+			json.Unmarshal(body[:n], &o)
+			ch <- o
+		}
+	}()
+
+	return ch
+}
+
+// waitFor waits for an update with id, discarding any other update.
+func waitFor(t *testing.T, ch chan model.Order, id uint8) model.Order {
+	for {
+		select {
+		case <-time.After(time.Millisecond * 100):
+			t.Fatalf("timeout while waiting for update %d", id)
+		case o := <-ch:
+			if o.ID == id {
+				return o
+			}
+		}
+	}
+}
+
+func newDrainedGame(t *testing.T, opts model.GameOptions) (*Game, *Client, *Client, chan model.Order, chan model.Order) {
+	r1, w1 := io.Pipe()
+	r2, w2 := io.Pipe()
+
+	c1, c2 := &Client{W: w1}, &Client{W: w2}
+	g, err := NewGameWithOptions(c1, c2, opts)
+	if err != nil {
+		t.Fatalf("NewGame: %s", err.Error())
+	}
+
+	ch1, ch2 := drain(r1), drain(r2)
+	g.SwitchTurn()
+
+	return g, c1, c2, ch1, ch2
+}
+
+func doMoveOrder(t *testing.T, cl *Client, id int8, dst board.Point) {
+	body, _ := json.Marshal(model.MoveOrder{ID: id, Dst: dst})
+	err := cl.Do(model.Order{ID: model.OrMove, Data: body})
+	if err != nil {
+		t.Fatalf("cl.Do: %s", err.Error())
+	}
+}
+
+func TestCommandTakeback(t *testing.T) {
+	g, c1, c2, _, ch2 := newDrainedGame(t, model.GameOptions{})
+
+	err := c1.Do(model.Order{ID: model.OrTakeback})
+	if err != ErrTakebackNil {
+		t.Fatalf("cl.Do: takeback without a move, want: %v - have: %v", ErrTakebackNil, err)
+	}
+
+	before := g.brd.String()
+	doMoveOrder(t, c1, 20, board.Point{X: 4, Y: 4})
+
+	err = c1.Do(model.Order{ID: model.OrTakeback})
+	if err != nil {
+		t.Fatalf("cl.Do: %s", err.Error())
+	}
+	waitFor(t, ch2, model.OrTakeback)
+
+	// the requester cannot accept their own takeback
+	body, _ := json.Marshal(model.ReplyOrder{Accept: true})
+	err = c1.Do(model.Order{ID: model.OrTakebackReply, Data: body})
+	if err != ErrTakebackPending {
+		t.Fatalf("cl.Do: want: %v - have: %v", ErrTakebackPending, err)
+	}
+
+	err = c2.Do(model.Order{ID: model.OrTakebackReply, Data: body})
+	if err != nil {
+		t.Fatalf("cl.Do: %s", err.Error())
+	}
+	waitFor(t, ch2, model.OrBoard)
+
+	if g.brd.String() != before {
+		t.Fatalf("takeback does not revert the board\n%s", g.brd.String())
+	}
+	if !g.IsTurn(c1) {
+		t.Fatalf("takeback does not revert the turn")
+	}
+	if len(g.History()) != 0 {
+		t.Fatalf("takeback does not revert the history")
+	}
+}
+
+func TestCommandTakebackOnMove(t *testing.T) {
+	g, c1, c2, ch1, ch2 := newDrainedGame(t, model.GameOptions{})
+
+	before := g.brd.String()
+	doMoveOrder(t, c1, 20, board.Point{X: 4, Y: 4})
+
+	// black has no move of their own to take back
+	err := c2.Do(model.Order{ID: model.OrTakeback})
+	if err != ErrTakebackNil {
+		t.Fatalf("cl.Do: want: %v - have: %v", ErrTakebackNil, err)
+	}
+
+	doMoveOrder(t, c2, 12, board.Point{X: 4, Y: 3})
+
+	// white is on move, so both e7e5 and e2e4 are taken back
+	err = c1.Do(model.Order{ID: model.OrTakeback})
+	if err != nil {
+		t.Fatalf("cl.Do: %s", err.Error())
+	}
+	waitFor(t, ch2, model.OrTakeback)
+
+	body, _ := json.Marshal(model.ReplyOrder{Accept: true})
+	err = c2.Do(model.Order{ID: model.OrTakebackReply, Data: body})
+	if err != nil {
+		t.Fatalf("cl.Do: %s", err.Error())
+	}
+	waitFor(t, ch1, model.OrBoard)
+
+	if g.brd.String() != before {
+		t.Fatalf("takeback does not revert the requester's move\n%s", g.brd.String())
+	}
+	if !g.IsTurn(c1) {
+		t.Fatalf("takeback does not give the requester their turn back")
+	}
+	if len(g.History()) != 0 {
+		t.Fatalf("takeback does not revert the history: %v", g.History())
+	}
+}
+
+func TestCommandTakebackRated(t *testing.T) {
+	_, c1, _, _, _ := newDrainedGame(t, model.GameOptions{Rated: true})
+
+	doMoveOrder(t, c1, 20, board.Point{X: 4, Y: 4})
+
+	err := c1.Do(model.Order{ID: model.OrTakeback})
+	if err != ErrTakebackRated {
+		t.Fatalf("cl.Do: want: %v - have: %v", ErrTakebackRated, err)
+	}
+}
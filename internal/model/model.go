@@ -1,6 +1,8 @@
 package model
 
 import (
+	"time"
+
 	"github.com/toms1441/chess-server/internal/board"
 )
 
@@ -35,12 +37,255 @@ type Possible struct {
 
 // Watchable is a game that could be spectated
 type Watchable struct {
-	P1  Profile      `json:"p1"`
-	P2  Profile      `json:"p2"`
-	Brd *board.Board `json:"brd,omitempty"`
+	ID      string       `json:"id,omitempty"`
+	P1      Profile      `json:"p1"`
+	P2      Profile      `json:"p2"`
+	Brd     *board.Board `json:"brd,omitempty"`
+	Opening *Opening     `json:"opening,omitempty"`
+}
+
+// RatedProfile is a profile along with the user's rating in each time control category.
+type RatedProfile struct {
+	Profile
+	Ratings map[string]int `json:"ratings,omitempty"`
+}
+
+// RatedWatchable is a watchable game along with the ratings of both players.
+type RatedWatchable struct {
+	Watchable
+	P1 RatedProfile `json:"p1"`
+	P2 RatedProfile `json:"p2"`
+}
+
+// RatingChange is how a rated game changed the rating of both players.
+type RatingChange struct {
+	// Category is the time control category of the game, such as blitz
+	Category string `json:"category"`
+	// P1 and P2 are the new ratings
+	P1 int `json:"p1"`
+	P2 int `json:"p2"`
+	// D1 and D2 are the rating deltas
+	D1 int `json:"d1"`
+	D2 int `json:"d2"`
+}
+
+// Ranking is a player's place in a category's leaderboard.
+type Ranking struct {
+	Profile
+	Rank   int `json:"rank"`
+	Rating int `json:"rating"`
+}
+
+// Result is a finished game, it's kept to compute the statistics of players.
+type Result struct {
+	ID string  `json:"id"`
+	P1 Profile `json:"p1"`
+	P2 Profile `json:"p2"`
+	// Reason is one of Done*
+	Reason   uint8    `json:"reason"`
+	Category string   `json:"category"`
+	Rated    bool     `json:"rated"`
+	Opening  *Opening `json:"opening,omitempty"`
+	// Moves is the number of full moves played
+	Moves int       `json:"moves"`
+	Ended time.Time `json:"ended"`
+}
+
+// OpeningStats is how many games a player played with an opening.
+type OpeningStats struct {
+	Opening
+	Games int `json:"games"`
+}
+
+// Stats are the statistics of a player's finished games.
+type Stats struct {
+	Games  int `json:"games"`
+	Wins   int `json:"wins"`
+	Losses int `json:"losses"`
+	Draws  int `json:"draws"`
+	// Openings are the player's most played openings, most played first
+	Openings []OpeningStats `json:"openings"`
+	// AverageMoves is the average length of the player's games, in full moves
+	AverageMoves float64 `json:"average_moves"`
+	// Form is the results of the player's latest games, latest first. Each result is one of Form*
+	Form []string `json:"form"`
+}
+
+const (
+	FormWin  = "win"
+	FormLoss = "loss"
+	FormDraw = "draw"
+)
+
+// VariantStandard is the standard chess variant, and the default one.
+const VariantStandard = "standard"
+
+// TimeControl is the time each player has for the whole game. A zero TimeControl means the game is untimed.
+type TimeControl struct {
+	// Base is the starting time of each player, in seconds.
+	Base int `json:"base"`
+	// Increment is the time added to the player's clock after each move, in seconds.
+	Increment int `json:"increment"`
+}
+
+// Clock is the remaining time of each player, in milliseconds.
+type Clock struct {
+	P1 int64 `json:"p1"`
+	P2 int64 `json:"p2"`
+}
+
+// GameOptions are the settings of a game.
+type GameOptions struct {
+	// Rated games affect the players' ratings, and do not allow takebacks.
+	Rated       bool        `json:"rated"`
+	TimeControl TimeControl `json:"time_control"`
+	Variant     string      `json:"variant"`
+	// FEN is the starting position, empty means the standard one.
+	FEN string `json:"fen,omitempty"`
+	// Handicap is nil for even games.
+	Handicap *Handicap `json:"handicap,omitempty"`
+	// Days is the number of days each side has per move in correspondence games, zero means a live game. It takes precedence over TimeControl.
+	Days int `json:"days,omitempty"`
+}
+
+// Handicap is given by the stronger player to even out an odds game.
+type Handicap struct {
+	// Odds is the material given, one of Odds*. Empty means only extra time is given.
+	Odds string `json:"odds,omitempty"`
+	// Time is the extra time the weaker player gets, in seconds. Only used in timed games.
+	Time int `json:"time,omitempty"`
+	// P1 is the player that gives the handicap, only used for games. Invites always have the inviter giving the handicap.
+	P1 bool `json:"p1"`
+}
+
+const (
+	OddsKnight      = "knight"        // the queen's knight is removed
+	OddsRook        = "rook"          // the queen's rook is removed
+	OddsQueen       = "queen"         // the queen is removed
+	OddsPawnAndMove = "pawn_and_move" // the f pawn is removed, and the opponent moves first
+)
+
+// Seek is posted by a user looking for a game, the server pairs it with a compatible seek.
+type Seek struct {
+	TimeControl TimeControl `json:"time_control"`
+	// Variant is empty for the standard variant
+	Variant string `json:"variant,omitempty"`
+	Rated   bool   `json:"rated"`
+	// MinRating and MaxRating are the range of the opponent's rating, zero means no limit.
+	MinRating int `json:"min_rating,omitempty"`
+	MaxRating int `json:"max_rating,omitempty"`
+	// Color is the seeker's color, one of Color*. Empty means any color.
+	Color string `json:"color,omitempty"`
+}
+
+// Challenge is an open challenge, which anyone with its link could accept.
+type Challenge struct {
+	TimeControl TimeControl `json:"time_control"`
+	// Variant is empty for the standard variant
+	Variant string `json:"variant,omitempty"`
+	// Color is the challenger's color, one of Color*. Empty means a random color.
+	Color string `json:"color,omitempty"`
+	// Days is the number of days per move, zero means a live game.
+	Days int `json:"days,omitempty"`
+}
+
+// OpenChallenge is a challenge waiting for an opponent, it's shared via its link.
+type OpenChallenge struct {
+	Challenge
+	Code string `json:"code"`
+	URL  string `json:"url"`
+	// Profile is the challenger
+	Profile Profile   `json:"profile"`
+	Expires time.Time `json:"expires"`
+}
+
+// FriendStatus is a friend, and whether they're online.
+type FriendStatus struct {
+	Profile
+	Online bool `json:"online"`
+}
+
+// Friends are the friends and the blocked players of a user.
+type Friends struct {
+	Friends []FriendStatus `json:"friends"`
+	Blocked []Profile      `json:"blocked"`
+}
+
+// PendingInvite is an invite that wasn't accepted yet. Profile is the inviter for incoming invites, and the invitee for outgoing invites.
+type PendingInvite struct {
+	InviteOrder
+	Expires time.Time `json:"expires"`
+}
+
+// Invites are the pending invites of a user.
+type Invites struct {
+	Incoming []PendingInvite `json:"incoming"`
+	Outgoing []PendingInvite `json:"outgoing"`
+}
+
+// Castling is whether each player could still castle.
+type Castling struct {
+	P1 bool `json:"p1"`
+	P2 bool `json:"p2"`
+}
+
+// Snapshot is the entire state of a game in progress, used to restore games across restarts.
+type Snapshot struct {
+	ID       string       `json:"id"`
+	P1       Profile      `json:"p1"`
+	P2       Profile      `json:"p2"`
+	Brd      *board.Board `json:"brd"`
+	Turn     bool         `json:"turn"`
+	Castling Castling     `json:"castling"`
+	// Clock is nil whenever the game is untimed
+	Clock   *Clock      `json:"clock,omitempty"`
+	History []History   `json:"history"`
+	Chat    []ChatOrder `json:"chat"`
+	Options GameOptions `json:"options"`
 }
 
 // Generic is a struct used for generic stuff. If a model *only* uses ID, then it should use generic.
 type Generic struct {
 	ID string `json:"id"`
 }
+
+// Puzzle is a puzzle that is being solved by the user.
+type Puzzle struct {
+	ID     string   `json:"id"`
+	Rating int      `json:"rating"`
+	Themes []string `json:"themes"`
+	// P1 is the user's pieces
+	P1  bool         `json:"p1"`
+	Brd *board.Board `json:"brd"`
+}
+
+// PuzzleState is the state of the puzzle after a move.
+type PuzzleState struct {
+	Done   bool `json:"done"`
+	Solved bool `json:"solved"`
+}
+
+// PuzzleRecord is the list of puzzle ids solved and failed by a user.
+type PuzzleRecord struct {
+	Solved []string `json:"solved"`
+	Failed []string `json:"failed"`
+}
+
+// Opening is a chess opening, classified by it's ECO code.
+type Opening struct {
+	ECO  string `json:"eco"`
+	Name string `json:"name"`
+}
+
+// History is a single move in the game's history.
+type History struct {
+	ID  int8        `json:"id"`
+	Src board.Point `json:"src"`
+	Dst board.Point `json:"dst"`
+	// Kind is set whenever a pawn gets promoted in this move
+	Kind uint8 `json:"kind,omitempty"`
+	// Notation is the move in long algebraic notation, i.e e2e4 or e7e8q. Castling is notated as the king's move.
+	Notation string `json:"notation"`
+	// Check is set whenever the move puts the opponent's king in check
+	Check bool `json:"check,omitempty"`
+}
@@ -12,6 +12,8 @@ import (
 type Order struct {
 	ID   uint8           `json:"id" validate:"required"`
 	Data json.RawMessage `json:"data" validate:"required"`
+	// Game is the ID of the game an update belongs to, as users could be in several games. Puzzle updates have the puzzle's ID.
+	Game string `json:"game,omitempty"`
 	// Parameter primarily used in game.
 	Parameter interface{} `json:"-"`
 }
@@ -34,12 +36,54 @@ const (
 	OrPromotion
 	// Castling is the act of switching the king and the rook's positions. This is only legal when the king and the rook haven't moved, and nothing is in between them. [O]
 	OrCastling
-	// Checkmate is sent whenever the king is in danger and needs to move.
+	// Checkmate is no longer sent, since it was sent whenever the king was in check. See Check, and the Done*Mates reasons.
+	// It's kept so that the order ids don't change.
 	OrCheckmate
 	// Done is sent whenever a game ends, or when the player wants to leave the game. [O]
 	OrDone
+	// Takeback is received from a player that wants to take back their last move, along with the opponent's reply to it if there's one. When sent to the opponent, it's an indication that they need to reply with TakebackReply. [O]
+	OrTakeback
+	// TakebackReply is received from the opponent to accept or decline a takeback. If declined, it's sent to the player that requested the takeback. [O]
+	OrTakebackReply
+	// Board is sent whenever the board changes without a move, such as a takeback. [U]
+	OrBoard
+	// Rematch is received from a player that wants a rematch after a game ends. When sent to the opponent, it's an indication that they could reply with Rematch to start the game. [O]
+	OrRematch
+	// Disconnected is sent whenever a player loses their connection. The player could reconnect within a grace period. [U]
+	OrDisconnected
+	// Reconnected is sent whenever a disconnected player reconnects. [U]
+	OrReconnected
+	// History is sent to a player whenever they reconnect, it contains all the moves played in the game. [U]
+	OrHistory
+	// Abandoned is sent to a player whenever their opponent has been idle or disconnected beyond the limit on their turn. The player could then send Claim. [U]
+	OrAbandoned
+	// Claim is received from a player to claim victory or a draw after their opponent abandoned the game. [C]
+	OrClaim
+	// Chat is received from a player or a spectator. Players' messages are sent to the opponent, and spectators' messages are sent to the other spectators. [O]
+	OrChat
+	// Premove is sent to a player whenever their premove fires, or gets cancelled as it's no longer legal. Premoves are sent via Move while it's the opponent's turn. [U]
+	OrPremove
+	// Check is sent whenever a king is in check, with the pieces checking it. [U]
+	OrCheck
+	// Adjourn is received from a player that wants to suspend the game. When sent to the opponent, it's an indication that they need to reply with AdjournReply. [O]
+	OrAdjourn
+	// AdjournReply is received from the opponent to accept or decline an adjournment. If declined, it's sent to the player that requested it. If accepted, the game ends with DoneAdjourned until either player resumes it. [O]
+	OrAdjournReply
+	// InviteDeclined is sent to the inviter whenever the invitee declines their invite. [U]
+	OrInviteDeclined
+	// InviteCancelled is sent to the invitee whenever the inviter cancels their invite. [U]
+	OrInviteCancelled
+	// InviteExpired is sent to both the inviter and the invitee whenever an invite expires before it's accepted. [U]
+	OrInviteExpired
+	// FriendOnline is sent to a user whenever one of their friends connects. [U]
+	OrFriendOnline
+	// FriendOffline is sent to a user whenever one of their friends leaves. [U]
+	OrFriendOffline
 )
 
+// OrCustom is the first id of custom orders, which are registered via game.RegisterCommand and game.RegisterUpdate. Ids below it are reserved.
+const OrCustom uint8 = 128
+
 // [U]
 type CredentialsOrder struct {
 	Profile Profile `json:"profile"`
@@ -50,15 +94,43 @@ type CredentialsOrder struct {
 type InviteOrder struct {
 	// Profile is only used for updates...
 	Profile Profile `json:"profile" validate:"required"`
+	// Color is the inviter's color, one of Color*. Empty means ColorRandom.
+	Color string `json:"color,omitempty"`
+	// FEN is an optional starting position, empty means the standard one.
+	FEN string `json:"fen,omitempty"`
+	// Handicap is given by the inviter, nil means an even game.
+	Handicap *Handicap `json:"handicap,omitempty"`
+	// Days is the number of days per move, zero means a live game.
+	Days int `json:"days,omitempty"`
+	// TimeControl is the clock of the game, zero means an untimed game.
+	TimeControl TimeControl `json:"time_control"`
+	// Rated games affect the players' ratings, they must start from the standard position.
+	Rated bool `json:"rated"`
+}
+
+// [U]
+type FriendOrder struct {
+	// Profile is the friend for updates, and the player to befriend or block for requests.
+	Profile Profile `json:"profile" validate:"required"`
 }
 
+const (
+	ColorWhite  = "white"
+	ColorBlack  = "black"
+	ColorRandom = "random"
+)
+
 // [U]
 type GameOrder struct {
+	// ID is the game's ID
+	ID string `json:"id,omitempty"`
 	// which pieces are yours
 	P1 *bool `json:"p1,omitempty"`
 	// Profile is the other player's profile
 	Profile *Profile     `json:"profile,omitempty"`
 	Brd     *board.Board `json:"brd"`
+	// Opening is the opening played so far, if it's known
+	Opening *Opening `json:"opening,omitempty"`
 }
 
 // [O]
@@ -70,6 +142,10 @@ type MoveOrder struct {
 // [U]
 type TurnOrder struct {
 	P1 bool `json:"p1"`
+	// Clock is only set in timed games
+	Clock *Clock `json:"clock,omitempty"`
+	// Opening is the opening played so far, it's nil whenever it's unknown. It's sent with every turn, so that it's updated as soon as it changes.
+	Opening *Opening `json:"opening,omitempty"`
 }
 
 // [O]
@@ -90,9 +166,73 @@ type CastlingOrder struct {
 // [U]
 type CheckmateOrder TurnOrder
 
+// [O]
+type TakebackOrder TurnOrder
+
+// [O]
+type ReplyOrder struct {
+	Accept bool `json:"accept"`
+}
+
+// [U]
+type BoardOrder struct {
+	Brd *board.Board `json:"brd"`
+}
+
+// [U]
+type DisconnectedOrder TurnOrder
+
+// [U]
+type ReconnectedOrder TurnOrder
+
+// [U]
+type HistoryOrder struct {
+	History []History `json:"history"`
+}
+
+// [U]
+type AbandonedOrder TurnOrder
+
+// [C]
+type ClaimOrder struct {
+	// Draw claims a draw instead of a victory
+	Draw bool `json:"draw"`
+}
+
+// [O]
+type ChatOrder struct {
+	Message string `json:"message"`
+	// P1 is the player that sent the message, only used for updates
+	P1 bool `json:"p1"`
+	// Spectator is set whenever the message is from the spectator room, only used for updates
+	Spectator bool `json:"spectator,omitempty"`
+}
+
+// [U]
+type CheckOrder struct {
+	// P1 is the player in check
+	P1 bool `json:"p1"`
+	// King is the square of the king in check
+	King board.Point `json:"king"`
+	// Pieces are the ids of the pieces checking the king
+	Pieces []int8 `json:"pieces"`
+}
+
+// [O]
+type AdjournOrder TurnOrder
+
+// [U]
+type PremoveOrder struct {
+	MoveOrder
+	// Fired is false whenever the premove is cancelled
+	Fired bool `json:"fired"`
+}
+
 // [O]
 type DoneOrder struct {
 	Reason uint8 `json:"reason"`
+	// Ratings is set whenever the game is rated, it's sent in a second DoneOrder after the ratings are updated
+	Ratings *RatingChange `json:"ratings,omitempty"`
 }
 
 const (
@@ -102,4 +242,12 @@ const (
 	DoneWhiteForfeit                   // white forfeits/left
 	DoneBlackForfeit                   // black forfeits/left
 	DoneSpectatorLeft                  // spectator left. only affects spectator
+	DoneWhiteTimeout                   // white ran out of time
+	DoneBlackTimeout                   // black ran out of time
+	DoneWhiteAbandon                   // white abandoned the game, and black claimed victory
+	DoneBlackAbandon                   // black abandoned the game, and white claimed victory
+	DoneAbandonDraw                    // a player abandoned the game, and their opponent claimed a draw
+	DoneWhiteMates                     // white checkmated black
+	DoneBlackMates                     // black checkmated white
+	DoneAdjourned                      // both players agreed to suspend the game, it could be resumed later
 )
@@ -0,0 +1,11 @@
+package puzzle
+
+import "errors"
+
+var (
+	ErrFormat      = errors.New("puzzle file format is invalid")
+	ErrPuzzleNil   = errors.New("puzzle is nil")
+	ErrPuzzleDone  = errors.New("puzzle is done")
+	ErrIllegalMove = errors.New("illegal move")
+	ErrWrongMove   = errors.New("wrong move, puzzle failed")
+)
@@ -0,0 +1,224 @@
+// Package puzzle provides chess puzzles loaded from a local file, and sessions to solve them move by move.
+//
+// Puzzles follow the lichess puzzle format: the FEN is the position before the opponent's move, and the first move in Moves is the opponent's move.
+// Every odd move afterwards is the player's, and every even move is the opponent's reply.
+package puzzle
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+type Puzzle struct {
+	ID     string   `json:"id"`
+	FEN    string   `json:"fen"`
+	Moves  []string `json:"moves"`
+	Rating int      `json:"rating"`
+	Themes []string `json:"themes"`
+}
+
+var (
+	mtx     sync.RWMutex
+	puzzles = []Puzzle{}
+	byid    = map[string]int{}
+)
+
+// Load loads the puzzles from a file, depending on the file's extension. It supports .json and .csv files, and replaces any previously loaded puzzles.
+func Load(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var list []Puzzle
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		list, err = ReadJSON(file)
+	case ".csv":
+		list, err = ReadCSV(file)
+	default:
+		return ErrFormat
+	}
+	if err != nil {
+		return err
+	}
+
+	Set(list)
+	return nil
+}
+
+// Set replaces the loaded puzzles with list. Puzzles without a fen or a move are skipped.
+func Set(list []Puzzle) {
+	mtx.Lock()
+	defer mtx.Unlock()
+
+	puzzles = []Puzzle{}
+	byid = map[string]int{}
+	for _, v := range list {
+		if len(v.FEN) == 0 || len(v.Moves) < 2 {
+			continue
+		}
+
+		byid[v.ID] = len(puzzles)
+		puzzles = append(puzzles, v)
+	}
+}
+
+// ReadJSON reads a JSON array of puzzles.
+func ReadJSON(rd io.Reader) ([]Puzzle, error) {
+	list := []Puzzle{}
+	if err := json.NewDecoder(rd).Decode(&list); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// ReadCSV reads puzzles in CSV format. The first row must be a header, with the following columns(in any order): PuzzleId, FEN, Moves, Rating, Themes.
+// Moves and Themes are separated by spaces, any other column is ignored.
+func ReadCSV(rd io.Reader) ([]Puzzle, error) {
+	reader := csv.NewReader(rd)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, err
+	}
+
+	cols := map[string]int{}
+	for k, v := range header {
+		cols[strings.ToLower(strings.TrimSpace(v))] = k
+	}
+
+	id, ok := cols["puzzleid"]
+	if !ok {
+		id, ok = cols["id"]
+	}
+	fen, okfen := cols["fen"]
+	moves, okmoves := cols["moves"]
+	if !ok || !okfen || !okmoves {
+		return nil, ErrFormat
+	}
+
+	get := func(record []string, name string) string {
+		k, ok := cols[name]
+		if !ok || k >= len(record) {
+			return ""
+		}
+
+		return record[k]
+	}
+
+	list := []Puzzle{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		if len(record) <= id || len(record) <= fen || len(record) <= moves {
+			return nil, ErrFormat
+		}
+
+		rating, _ := strconv.Atoi(get(record, "rating"))
+		list = append(list, Puzzle{
+			ID:     record[id],
+			FEN:    record[fen],
+			Moves:  strings.Fields(record[moves]),
+			Rating: rating,
+			Themes: strings.Fields(get(record, "themes")),
+		})
+	}
+
+	return list, nil
+}
+
+// Get returns the puzzle that has id.
+func Get(id string) (Puzzle, error) {
+	mtx.RLock()
+	defer mtx.RUnlock()
+
+	k, ok := byid[id]
+	if !ok {
+		return Puzzle{}, ErrPuzzleNil
+	}
+
+	return puzzles[k], nil
+}
+
+// Random returns a random puzzle.
+func Random() (Puzzle, error) {
+	mtx.RLock()
+	defer mtx.RUnlock()
+
+	if len(puzzles) == 0 {
+		return Puzzle{}, ErrPuzzleNil
+	}
+
+	return puzzles[rand.Intn(len(puzzles))], nil
+}
+
+// Len returns the number of loaded puzzles
+func Len() int {
+	mtx.RLock()
+	defer mtx.RUnlock()
+
+	return len(puzzles)
+}
+
+var (
+	recmtx  sync.Mutex
+	records = map[model.Profile]*model.PuzzleRecord{}
+)
+
+// Record records the outcome of a puzzle for the profile.
+func Record(pro model.Profile, id string, solved bool) {
+	recmtx.Lock()
+	defer recmtx.Unlock()
+
+	rec, ok := records[pro]
+	if !ok {
+		rec = &model.PuzzleRecord{
+			Solved: []string{},
+			Failed: []string{},
+		}
+		records[pro] = rec
+	}
+
+	if solved {
+		rec.Solved = append(rec.Solved, id)
+	} else {
+		rec.Failed = append(rec.Failed, id)
+	}
+}
+
+// GetRecord returns a copy of the puzzle record of the profile.
+func GetRecord(pro model.Profile) model.PuzzleRecord {
+	recmtx.Lock()
+	defer recmtx.Unlock()
+
+	rec, ok := records[pro]
+	if !ok {
+		return model.PuzzleRecord{
+			Solved: []string{},
+			Failed: []string{},
+		}
+	}
+
+	return model.PuzzleRecord{
+		Solved: append([]string{}, rec.Solved...),
+		Failed: append([]string{}, rec.Failed...),
+	}
+}
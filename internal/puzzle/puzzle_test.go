@@ -0,0 +1,218 @@
+package puzzle
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/toms1441/chess-server/internal/board"
+	"github.com/toms1441/chess-server/internal/model"
+	"github.com/toms1441/chess-server/internal/model/local"
+)
+
+const csvPuzzles = `PuzzleId,FEN,Moves,Rating,RatingDeviation,Themes
+00001,6k1/5ppp/8/8/8/8/5PPP/R5K1 b - - 0 1,g8h8 a1a8,600,75,mate mateIn1 backRankMate
+00002,6k1/5ppp/8/8/8/8/5PPP/R5K1 b - - 0 1,g8h8 a1a2 h7h6 a2a8,900,75,endgame
+`
+
+const jsonPuzzles = `[{"id":"00003","fen":"6k1/5ppp/8/8/8/8/5PPP/R5K1 b - - 0 1","moves":["g8h8","a1a8"],"rating":600,"themes":["mate"]}]`
+
+func getID(t *testing.T, s *Session, str string) int8 {
+	pnt, err := board.ParseNotation(str)
+	if err != nil {
+		t.Fatalf("board.ParseNotation: %s", err.Error())
+	}
+
+	id, _, err := s.Board().Get(pnt)
+	if err != nil {
+		t.Fatalf("board.Get: %s", err.Error())
+	}
+
+	return id
+}
+
+func getPoint(t *testing.T, str string) board.Point {
+	pnt, err := board.ParseNotation(str)
+	if err != nil {
+		t.Fatalf("board.ParseNotation: %s", err.Error())
+	}
+
+	return pnt
+}
+
+func TestReadCSV(t *testing.T) {
+	list, err := ReadCSV(strings.NewReader(csvPuzzles))
+	if err != nil {
+		t.Fatalf("ReadCSV: %s", err.Error())
+	}
+
+	if len(list) != 2 {
+		t.Fatalf("len(list): want: 2 - have: %d", len(list))
+	}
+
+	pz := list[0]
+	if pz.ID != "00001" || pz.Rating != 600 || len(pz.Moves) != 2 || len(pz.Themes) != 3 {
+		t.Fatalf("puzzle is not parsed properly: %v", pz)
+	}
+
+	_, err = ReadCSV(strings.NewReader("a,b,c\n1,2,3\n"))
+	if err == nil {
+		t.Fatalf("ReadCSV: missing columns do not return an error")
+	}
+}
+
+func TestReadJSON(t *testing.T) {
+	list, err := ReadJSON(strings.NewReader(jsonPuzzles))
+	if err != nil {
+		t.Fatalf("ReadJSON: %s", err.Error())
+	}
+
+	Set(list)
+	if Len() != 1 {
+		t.Fatalf("Len: want: 1 - have: %d", Len())
+	}
+
+	pz, err := Get("00003")
+	if err != nil {
+		t.Fatalf("Get: %s", err.Error())
+	}
+	if pz.FEN != list[0].FEN {
+		t.Fatalf("Get: returns a different puzzle")
+	}
+
+	_, err = Get("nope")
+	if err == nil {
+		t.Fatalf("Get: invalid id does not return an error")
+	}
+}
+
+func TestSessionSolve(t *testing.T) {
+	list, _ := ReadCSV(strings.NewReader(csvPuzzles))
+
+	s, err := NewSession(list[1])
+	if err != nil {
+		t.Fatalf("NewSession: %s", err.Error())
+	}
+
+	if !s.P1() {
+		t.Fatalf("player should be white")
+	}
+
+	rook := getID(t, s, "a1")
+
+	// illegal move, does not fail the puzzle
+	_, err = s.Move(model.MoveOrder{ID: rook, Dst: getPoint(t, "b2")})
+	if !errors.Is(err, ErrIllegalMove) {
+		t.Fatalf("s.Move: want: %v - have: %v", ErrIllegalMove, err)
+	}
+
+	orders, err := s.Move(model.MoveOrder{ID: rook, Dst: getPoint(t, "a2")})
+	if err != nil {
+		t.Fatalf("s.Move: %s", err.Error())
+	}
+
+	if len(orders) != 1 || orders[0].ID != model.OrMove {
+		t.Fatalf("s.Move: opponent's reply is not returned: %v", orders)
+	}
+
+	_, pec, err := s.Board().Get(getPoint(t, "h6"))
+	if err != nil || pec.Kind != board.Pawn {
+		t.Fatalf("opponent's reply is not played")
+	}
+
+	_, err = s.Move(model.MoveOrder{ID: rook, Dst: getPoint(t, "a8")})
+	if err != nil {
+		t.Fatalf("s.Move: %s", err.Error())
+	}
+
+	if !s.Done() || !s.Solved() {
+		t.Fatalf("puzzle should be solved")
+	}
+
+	Record(local.NewUser(), s.Puzzle().ID, s.Solved())
+}
+
+func TestSessionFail(t *testing.T) {
+	list, _ := ReadCSV(strings.NewReader(csvPuzzles))
+
+	s, err := NewSession(list[0])
+	if err != nil {
+		t.Fatalf("NewSession: %s", err.Error())
+	}
+
+	rook := getID(t, s, "a1")
+	_, err = s.Move(model.MoveOrder{ID: rook, Dst: getPoint(t, "b1")})
+	if !errors.Is(err, ErrWrongMove) {
+		t.Fatalf("s.Move: want: %v - have: %v", ErrWrongMove, err)
+	}
+
+	if !s.Done() || s.Solved() {
+		t.Fatalf("puzzle should be failed")
+	}
+
+	pro := local.NewUser()
+	Record(pro, s.Puzzle().ID, s.Solved())
+
+	rec := GetRecord(pro)
+	if len(rec.Failed) != 1 || len(rec.Solved) != 0 {
+		t.Fatalf("record is not saved: %v", rec)
+	}
+}
+
+func TestSessionCastling(t *testing.T) {
+	s, err := NewSession(Puzzle{
+		ID:    "castling",
+		FEN:   "r3k2r/pppppppp/8/8/8/8/8/4K3 w kq - 0 1",
+		Moves: []string{"e1d1", "e8g8", "d1e1"},
+	})
+	if err != nil {
+		t.Fatalf("NewSession: %s", err.Error())
+	}
+
+	king := getID(t, s, "e8")
+	rook := getID(t, s, "h8")
+
+	orders, err := s.Move(model.MoveOrder{ID: king, Dst: getPoint(t, "g8")})
+	if err != nil {
+		t.Fatalf("s.Move: %s", err.Error())
+	}
+	if len(orders) != 1 || orders[0].ID != model.OrMove {
+		t.Fatalf("s.Move: opponent's reply is not returned: %v", orders)
+	}
+
+	if getID(t, s, "g8") != king || getID(t, s, "f8") != rook {
+		t.Fatalf("castling is not played:\n%s", s.Board())
+	}
+
+	if !s.Done() || !s.Solved() {
+		t.Fatalf("puzzle should be solved")
+	}
+}
+
+func TestSessionEnPassant(t *testing.T) {
+	s, err := NewSession(Puzzle{
+		ID:    "enpassant",
+		FEN:   "4k3/8/8/8/1p6/8/P7/4K3 w - - 0 1",
+		Moves: []string{"a2a4", "b4a3", "e1d1"},
+	})
+	if err != nil {
+		t.Fatalf("NewSession: %s", err.Error())
+	}
+
+	pawn := getID(t, s, "b4")
+	_, err = s.Move(model.MoveOrder{ID: pawn, Dst: getPoint(t, "a3")})
+	if err != nil {
+		t.Fatalf("s.Move: %s", err.Error())
+	}
+
+	if getID(t, s, "a3") != pawn {
+		t.Fatalf("en passant is not played:\n%s", s.Board())
+	}
+	if _, _, err := s.Board().Get(getPoint(t, "a4")); err == nil {
+		t.Fatalf("en passant does not capture the pawn:\n%s", s.Board())
+	}
+
+	if !s.Done() || !s.Solved() {
+		t.Fatalf("puzzle should be solved")
+	}
+}
@@ -0,0 +1,264 @@
+package puzzle
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/toms1441/chess-server/internal/board"
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+var promotions = map[byte]uint8{
+	'q': board.Queen,
+	'r': board.Rook,
+	'b': board.Bishop,
+	'n': board.Knight,
+}
+
+// Session is a single attempt of a puzzle by a player.
+type Session struct {
+	pz  Puzzle
+	brd *board.Board
+	// p1 is the player's side
+	p1 bool
+	// index is the index of the next move in pz.Moves
+	index  int
+	done   bool
+	solved bool
+	// mtx protects the session from concurrent moves, such as two connections of the same user
+	mtx sync.Mutex
+}
+
+// move is a parsed move from the puzzle's solution, i.e "e7e8q"
+type move struct {
+	src  board.Point
+	dst  board.Point
+	kind uint8
+}
+
+func parseMove(str string) (move, error) {
+	if len(str) != 4 && len(str) != 5 {
+		return move{}, ErrFormat
+	}
+
+	src, err := board.ParseNotation(str[:2])
+	if err != nil {
+		return move{}, err
+	}
+	dst, err := board.ParseNotation(str[2:4])
+	if err != nil {
+		return move{}, err
+	}
+
+	mv := move{src: src, dst: dst}
+	if len(str) == 5 {
+		kind, ok := promotions[str[4]]
+		if !ok {
+			return move{}, ErrFormat
+		}
+
+		mv.kind = kind
+	}
+
+	return mv, nil
+}
+
+// NewSession creates a session for the puzzle, and plays the opponent's first move.
+func NewSession(pz Puzzle) (*Session, error) {
+	pos, err := board.ParseFEN(pz.FEN)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pz.Moves) < 2 {
+		return nil, ErrFormat
+	}
+
+	s := &Session{
+		pz:  pz,
+		brd: pos.Brd,
+		// the opponent moves first
+		p1: !pos.P1,
+	}
+
+	_, err = s.play()
+	if err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// play plays the next move in the solution, regardless of who's move it is. It returns the updates that describe the move.
+func (s *Session) play() ([]model.Order, error) {
+	if s.index >= len(s.pz.Moves) {
+		return nil, ErrPuzzleDone
+	}
+
+	mv, err := parseMove(s.pz.Moves[s.index])
+	if err != nil {
+		return nil, err
+	}
+
+	id, pec, err := s.brd.Get(mv.src)
+	if err != nil {
+		return nil, err
+	}
+
+	s.index++
+
+	if orders := s.special(id, pec, mv); orders != nil {
+		return orders, nil
+	}
+
+	orders := []model.Order{}
+	add := func(id uint8, v interface{}) {
+		orders = append(orders, newOrder(id, v))
+	}
+
+	// trust the puzzle file, even if the board does not support the move
+	if !s.brd.Move(id, mv.dst) {
+		s.brd.Set(id, mv.dst)
+	}
+	add(model.OrMove, model.MoveOrder{ID: id, Dst: mv.dst})
+
+	if mv.kind != board.Empty {
+		s.brd.SetKind(id, mv.kind)
+		add(model.OrPromotion, model.PromotionOrder{ID: id, Kind: mv.kind})
+	}
+
+	return orders, nil
+}
+
+func newOrder(id uint8, v interface{}) model.Order {
+	body, _ := json.Marshal(v)
+	return model.Order{ID: id, Data: body}
+}
+
+// special plays castling and en passant, as the board does not support them. It returns the updates that describe the move, or nil if mv is neither.
+func (s *Session) special(id int8, pec board.Piece, mv move) []model.Order {
+	diff := mv.dst.X - mv.src.X
+	switch {
+	case pec.Kind == board.King && (diff == 2 || diff == -2):
+		// castling, the same way game does it
+		src, rookx := board.Point{X: 7, Y: mv.src.Y}, int8(5)
+		if diff < 0 {
+			src, rookx = board.Point{X: 0, Y: mv.src.Y}, int8(3)
+		}
+
+		rookid, rook, err := s.brd.Get(src)
+		if err != nil || rook.Kind != board.Rook || rook.P1 != pec.P1 {
+			return nil
+		}
+
+		s.brd.Set(rookid, board.Point{X: rookx, Y: mv.src.Y})
+		s.brd.Set(id, mv.dst)
+
+		return []model.Order{newOrder(model.OrCastling, model.CastlingOrder{Src: id, Dst: rookid})}
+	case pec.Kind == board.Pawn && (diff == 1 || diff == -1):
+		// a pawn that moves diagonally to an empty square captures the pawn beside it
+		if _, _, err := s.brd.Get(mv.dst); err == nil {
+			return nil
+		}
+
+		capid, capt, err := s.brd.Get(board.Point{X: mv.dst.X, Y: mv.src.Y})
+		if err != nil || capt.Kind != board.Pawn || capt.P1 == pec.P1 {
+			return nil
+		}
+
+		s.brd.Set(capid, board.Point{X: -1, Y: -1})
+		s.brd.Set(id, mv.dst)
+
+		// the board is sent as well, as the captured pawn isn't on the destination
+		return []model.Order{
+			newOrder(model.OrMove, model.MoveOrder{ID: id, Dst: mv.dst}),
+			newOrder(model.OrBoard, model.BoardOrder{Brd: s.brd}),
+		}
+	}
+
+	return nil
+}
+
+// Move validates the player's move against the solution. If it's the right move, it plays the opponent's reply and returns the updates that describe it.
+// A legal move that isn't the solution fails the puzzle, while an illegal move returns ErrIllegalMove and could be retried.
+func (s *Session) Move(mo model.MoveOrder) ([]model.Order, error) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if s.done {
+		return nil, ErrPuzzleDone
+	}
+
+	if !board.BelongsTo(mo.ID, s.p1) {
+		return nil, ErrIllegalMove
+	}
+
+	pec, err := s.brd.GetByIndex(mo.ID)
+	if err != nil || !pec.Valid() {
+		return nil, ErrIllegalMove
+	}
+
+	mv, err := parseMove(s.pz.Moves[s.index])
+	if err != nil {
+		return nil, err
+	}
+
+	right := pec.Pos.Equal(mv.src) && mo.Dst.Equal(mv.dst)
+	// castling and en passant are trusted whenever they're the solution
+	if !right || s.special(mo.ID, pec, mv) == nil {
+		if !s.brd.Move(mo.ID, mo.Dst) {
+			return nil, ErrIllegalMove
+		}
+	}
+
+	s.index++
+	last := s.index >= len(s.pz.Moves)
+	if !right {
+		// any checkmate is a solution to a mate puzzle
+		if !last || !s.brd.FinalCheckmate(!s.p1) {
+			s.done = true
+			return nil, ErrWrongMove
+		}
+	} else if pec.Kind == board.Pawn && mv.kind != board.Empty {
+		s.brd.SetKind(mo.ID, mv.kind)
+	}
+
+	if last {
+		s.done, s.solved = true, true
+		return []model.Order{}, nil
+	}
+
+	orders, err := s.play()
+	if err != nil {
+		return nil, err
+	}
+
+	if s.index >= len(s.pz.Moves) {
+		s.done, s.solved = true, true
+	}
+
+	return orders, nil
+}
+
+// Puzzle returns the puzzle being solved
+func (s *Session) Puzzle() Puzzle { return s.pz }
+
+// Board returns the board of the session
+func (s *Session) Board() *board.Board { return s.brd }
+
+// P1 returns the player's side
+func (s *Session) P1() bool { return s.p1 }
+
+// Done returns true if the puzzle has been either solved or failed
+func (s *Session) Done() bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.done
+}
+
+// Solved returns true if the puzzle has been solved
+func (s *Session) Solved() bool {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+	return s.solved
+}
@@ -0,0 +1,65 @@
+package rating
+
+import "github.com/toms1441/chess-server/internal/model"
+
+// Categories of time controls, each has its own rating.
+const (
+	Bullet         = "bullet"
+	Blitz          = "blitz"
+	Rapid          = "rapid"
+	Classical      = "classical"
+	Correspondence = "correspondence"
+)
+
+// Categories is every category, from the fastest to the slowest.
+var Categories = []string{Bullet, Blitz, Rapid, Classical, Correspondence}
+
+// Category returns the category of the game's options. The estimated duration of a game is the base time plus 40 increments, the same as lichess.
+// Untimed games are classical.
+func Category(opts model.GameOptions) string {
+	if opts.Days > 0 {
+		return Correspondence
+	}
+
+	tc := opts.TimeControl
+	if tc.Base <= 0 {
+		return Classical
+	}
+
+	secs := tc.Base + tc.Increment*40
+	switch {
+	case secs < 180:
+		return Bullet
+	case secs < 480:
+		return Blitz
+	case secs < 1500:
+		return Rapid
+	}
+
+	return Classical
+}
+
+// ValidCategory returns true whenever c is one of Categories.
+func ValidCategory(c string) bool {
+	for _, v := range Categories {
+		if v == c {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Score returns player one's score in a game that ended with reason, one of model.Done*. It returns false whenever the game doesn't have a result, such as an adjourned game.
+func Score(reason uint8) (float64, bool) {
+	switch reason {
+	case model.DoneWhiteWon, model.DoneBlackForfeit, model.DoneBlackTimeout, model.DoneBlackAbandon, model.DoneWhiteMates:
+		return 1, true
+	case model.DoneBlackWon, model.DoneWhiteForfeit, model.DoneWhiteTimeout, model.DoneWhiteAbandon, model.DoneBlackMates:
+		return 0, true
+	case model.DoneStalemate, model.DoneAbandonDraw:
+		return 0.5, true
+	}
+
+	return 0, false
+}
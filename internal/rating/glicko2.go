@@ -0,0 +1,126 @@
+// Package rating provides Glicko-2 ratings of players, stored per profile and per time control category.
+//
+// Every rated game is treated as its own rating period, so ratings get updated as soon as a game ends.
+package rating
+
+import "math"
+
+const (
+	// DefaultRating is the rating of players that haven't played a rated game in the category.
+	DefaultRating = 1500
+	// DefaultDeviation is the rating deviation of new players.
+	DefaultDeviation = 350
+	// DefaultVolatility is the rating volatility of new players.
+	DefaultVolatility = 0.06
+
+	// tau constrains the change in volatility over time.
+	tau = 0.5
+	// scale converts between the Glicko and the Glicko-2 scale.
+	scale = 173.7178
+	// epsilon is the convergence tolerance of the volatility.
+	epsilon = 0.000001
+)
+
+// Rating is a player's Glicko-2 rating, in the Glicko scale.
+type Rating struct {
+	Rating     float64 `json:"rating"`
+	Deviation  float64 `json:"deviation"`
+	Volatility float64 `json:"volatility"`
+}
+
+// Default returns the rating of a new player.
+func Default() Rating {
+	return Rating{
+		Rating:     DefaultRating,
+		Deviation:  DefaultDeviation,
+		Volatility: DefaultVolatility,
+	}
+}
+
+// Result is a game against an opponent, Score is 1 for a win, 0.5 for a draw and 0 for a loss.
+type Result struct {
+	Opponent Rating
+	Score    float64
+}
+
+func g(phi float64) float64 {
+	return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+}
+
+func e(mu, muj, phij float64) float64 {
+	return 1 / (1 + math.Exp(-g(phij)*(mu-muj)))
+}
+
+// Update returns the rating after the results of a rating period. The deviation increases whenever there are no results.
+func Update(r Rating, results []Result) Rating {
+	mu := (r.Rating - DefaultRating) / scale
+	phi := r.Deviation / scale
+	sigma := r.Volatility
+
+	if len(results) == 0 {
+		phi = math.Sqrt(phi*phi + sigma*sigma)
+		return Rating{
+			Rating:     r.Rating,
+			Deviation:  math.Min(phi*scale, DefaultDeviation),
+			Volatility: sigma,
+		}
+	}
+
+	// the estimated variance, and the estimated improvement
+	var v, sum float64
+	for _, res := range results {
+		muj := (res.Opponent.Rating - DefaultRating) / scale
+		phij := res.Opponent.Deviation / scale
+
+		gj := g(phij)
+		ej := e(mu, muj, phij)
+
+		v += gj * gj * ej * (1 - ej)
+		sum += gj * (res.Score - ej)
+	}
+	v = 1 / v
+	delta := v * sum
+
+	// the new volatility, via the Illinois algorithm
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		d := phi*phi + v + ex
+		return ex*(delta*delta-phi*phi-v-ex)/(2*d*d) - (x-a)/(tau*tau)
+	}
+
+	A := a
+	var B float64
+	if delta*delta > phi*phi+v {
+		B = math.Log(delta*delta - phi*phi - v)
+	} else {
+		k := 1.0
+		for f(a-k*tau) < 0 {
+			k++
+		}
+		B = a - k*tau
+	}
+
+	fA, fB := f(A), f(B)
+	for math.Abs(B-A) > epsilon {
+		C := A + (A-B)*fA/(fB-fA)
+		fC := f(C)
+		if fC*fB <= 0 {
+			A, fA = B, fB
+		} else {
+			fA /= 2
+		}
+		B, fB = C, fC
+	}
+	sigma = math.Exp(A / 2)
+
+	phi = math.Sqrt(phi*phi + sigma*sigma)
+	phi = 1 / math.Sqrt(1/(phi*phi)+1/v)
+	mu += phi * phi * sum
+
+	return Rating{
+		Rating:     mu*scale + DefaultRating,
+		Deviation:  phi * scale,
+		Volatility: sigma,
+	}
+}
@@ -0,0 +1,44 @@
+package rating
+
+import (
+	"math"
+	"sort"
+
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+// Leaderboard returns the top n players in the category, highest rating first. Only players that have played a rated game in the category are ranked.
+func Leaderboard(cat string, n int) []model.Ranking {
+	mtx.RLock()
+	sl := []model.Ranking{}
+	for _, p := range players {
+		r, ok := p.Ratings[cat]
+		if !ok {
+			continue
+		}
+
+		sl = append(sl, model.Ranking{
+			Profile: p.Profile,
+			Rating:  int(math.Round(r.Rating)),
+		})
+	}
+	mtx.RUnlock()
+
+	sort.Slice(sl, func(i, j int) bool {
+		if sl[i].Rating != sl[j].Rating {
+			return sl[i].Rating > sl[j].Rating
+		}
+		// keep the order stable between calls
+		return key(sl[i].Profile) < key(sl[j].Profile)
+	})
+
+	if n > 0 && len(sl) > n {
+		sl = sl[:n]
+	}
+
+	for k := range sl {
+		sl[k].Rank = k + 1
+	}
+
+	return sl
+}
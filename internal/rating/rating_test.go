@@ -0,0 +1,130 @@
+package rating
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+func TestUpdate(t *testing.T) {
+	// the example in Glickman's paper
+	r := Update(Rating{Rating: 1500, Deviation: 200, Volatility: 0.06}, []Result{
+		{Opponent: Rating{Rating: 1400, Deviation: 30}, Score: 1},
+		{Opponent: Rating{Rating: 1550, Deviation: 100}, Score: 0},
+		{Opponent: Rating{Rating: 1700, Deviation: 300}, Score: 0},
+	})
+
+	if math.Abs(r.Rating-1464.06) > 0.01 {
+		t.Fatalf("rating: %f", r.Rating)
+	}
+	if math.Abs(r.Deviation-151.52) > 0.01 {
+		t.Fatalf("deviation: %f", r.Deviation)
+	}
+	if math.Abs(r.Volatility-0.05999) > 0.00001 {
+		t.Fatalf("volatility: %f", r.Volatility)
+	}
+
+	r = Update(r, nil)
+	if r.Deviation <= 151.52 {
+		t.Fatalf("deviation should increase without games: %f", r.Deviation)
+	}
+}
+
+func TestCategory(t *testing.T) {
+	tests := map[string]model.GameOptions{
+		Bullet:         {TimeControl: model.TimeControl{Base: 60}},
+		Blitz:          {TimeControl: model.TimeControl{Base: 180, Increment: 2}},
+		Rapid:          {TimeControl: model.TimeControl{Base: 600}},
+		Classical:      {TimeControl: model.TimeControl{Base: 1800, Increment: 20}},
+		Correspondence: {Days: 3},
+	}
+
+	for want, opts := range tests {
+		if got := Category(opts); got != want {
+			t.Fatalf("Category(%v): %s, want %s", opts, got, want)
+		}
+	}
+
+	if Category(model.GameOptions{}) != Classical {
+		t.Fatalf("untimed games should be classical")
+	}
+}
+
+func TestScore(t *testing.T) {
+	if s, ok := Score(model.DoneWhiteMates); !ok || s != 1 {
+		t.Fatalf("white mates: %f %t", s, ok)
+	}
+	if s, ok := Score(model.DoneWhiteTimeout); !ok || s != 0 {
+		t.Fatalf("white timeout: %f %t", s, ok)
+	}
+	if s, ok := Score(model.DoneStalemate); !ok || s != 0.5 {
+		t.Fatalf("stalemate: %f %t", s, ok)
+	}
+	if _, ok := Score(model.DoneAdjourned); ok {
+		t.Fatalf("adjourned games shouldn't be rated")
+	}
+}
+
+func TestRecord(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	File = filepath.Join(t.TempDir(), "ratings.json")
+	defer func() { File = "" }()
+
+	p1 := model.Profile{ID: "1", Platform: "local"}
+	p2 := model.Profile{ID: "2", Platform: "local"}
+
+	ch := Record(p1, p2, Blitz, 1)
+	if ch.Category != Blitz || ch.D1 <= 0 || ch.D2 >= 0 || ch.D1 != -ch.D2 {
+		t.Fatalf("unexpected change: %+v", ch)
+	}
+	if ch.P1 != DefaultRating+ch.D1 {
+		t.Fatalf("p1: %d", ch.P1)
+	}
+
+	m := Ratings(p1)
+	if m[Blitz] != ch.P1 || m[Bullet] != DefaultRating {
+		t.Fatalf("unexpected ratings: %v", m)
+	}
+
+	Reset()
+	err := Load(File)
+	if err != nil {
+		t.Fatalf("Load: %s", err.Error())
+	}
+	if Ratings(p2)[Blitz] != ch.P2 {
+		t.Fatalf("ratings weren't saved: %v", Ratings(p2))
+	}
+}
+
+func TestLeaderboard(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	p1 := model.Profile{ID: "1", Platform: "local", Username: "one"}
+	p2 := model.Profile{ID: "2", Platform: "local", Username: "two"}
+	p3 := model.Profile{ID: "3", Platform: "local", Username: "three"}
+
+	Record(p1, p2, Bullet, 0)
+	Record(p3, p1, Bullet, 1)
+
+	sl := Leaderboard(Bullet, 0)
+	if len(sl) != 3 {
+		t.Fatalf("len(sl): %d - want: 3", len(sl))
+	}
+	if sl[0].Rank != 1 || sl[2].Rank != 3 || sl[2].Username != p1.Username {
+		t.Fatalf("unexpected leaderboard: %+v", sl)
+	}
+	for k := 1; k < len(sl); k++ {
+		if sl[k].Rating > sl[k-1].Rating {
+			t.Fatalf("leaderboard isn't sorted: %+v", sl)
+		}
+	}
+
+	if len(Leaderboard(Bullet, 2)) != 2 || len(Leaderboard(Blitz, 0)) != 0 {
+		t.Fatalf("unexpected leaderboard size")
+	}
+}
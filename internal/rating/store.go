@@ -0,0 +1,157 @@
+package rating
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"sync"
+
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+// File is where ratings are saved after every rated game, empty means they're only kept in memory.
+var File string
+
+// player is a profile along with their rating in each category they've played.
+type player struct {
+	Profile model.Profile     `json:"profile"`
+	Ratings map[string]Rating `json:"ratings"`
+}
+
+var (
+	mtx sync.RWMutex
+	// players is keyed by key
+	players = map[string]*player{}
+)
+
+func key(pro model.Profile) string {
+	return pro.Platform + ":" + pro.ID
+}
+
+// Get returns the profile's rating in the category, or the default rating if they haven't played a rated game in it.
+func Get(pro model.Profile, cat string) Rating {
+	mtx.RLock()
+	defer mtx.RUnlock()
+
+	return get(pro, cat)
+}
+
+// get must be called with the lock held.
+func get(pro model.Profile, cat string) Rating {
+	p, ok := players[key(pro)]
+	if !ok {
+		return Default()
+	}
+
+	r, ok := p.Ratings[cat]
+	if !ok {
+		return Default()
+	}
+
+	return r
+}
+
+// Ratings returns the profile's rounded rating in every category.
+func Ratings(pro model.Profile) map[string]int {
+	m := make(map[string]int, len(Categories))
+	for _, c := range Categories {
+		m[c] = int(math.Round(Get(pro, c).Rating))
+	}
+
+	return m
+}
+
+// Rated returns pro along with their ratings.
+func Rated(pro model.Profile) model.RatedProfile {
+	return model.RatedProfile{
+		Profile: pro,
+		Ratings: Ratings(pro),
+	}
+}
+
+// Record updates the ratings of both players after a game in the category, score is p1's score. It returns the new ratings and the deltas.
+func Record(p1, p2 model.Profile, cat string, score float64) model.RatingChange {
+	mtx.Lock()
+	r1, r2 := get(p1, cat), get(p2, cat)
+
+	n1 := Update(r1, []Result{{Opponent: r2, Score: score}})
+	n2 := Update(r2, []Result{{Opponent: r1, Score: 1 - score}})
+
+	set := func(pro model.Profile, r Rating) {
+		p, ok := players[key(pro)]
+		if !ok {
+			p = &player{Ratings: map[string]Rating{}}
+			players[key(pro)] = p
+		}
+		// keep the latest username and picture
+		p.Profile = pro
+		p.Ratings[cat] = r
+	}
+	set(p1, n1)
+	set(p2, n2)
+	mtx.Unlock()
+
+	Save()
+
+	return model.RatingChange{
+		Category: cat,
+		P1:       int(math.Round(n1.Rating)),
+		P2:       int(math.Round(n2.Rating)),
+		D1:       int(math.Round(n1.Rating)) - int(math.Round(r1.Rating)),
+		D2:       int(math.Round(n2.Rating)) - int(math.Round(r2.Rating)),
+	}
+}
+
+// Reset removes every rating, it's used by tests.
+func Reset() {
+	mtx.Lock()
+	players = map[string]*player{}
+	mtx.Unlock()
+}
+
+// Save writes every rating to File.
+func Save() error {
+	if len(File) == 0 {
+		return nil
+	}
+
+	mtx.RLock()
+	body, err := json.Marshal(players)
+	mtx.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	tmp := File + ".tmp"
+	err = os.WriteFile(tmp, body, 0600)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, File)
+}
+
+// Load reads the ratings saved in path, and saves them to path from now on. It's not an error if path doesn't exist.
+func Load(path string) error {
+	File = path
+
+	body, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	m := map[string]*player{}
+	err = json.Unmarshal(body, &m)
+	if err != nil {
+		return err
+	}
+
+	mtx.Lock()
+	players = m
+	mtx.Unlock()
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/toms1441/chess-server/internal/game"
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+// findUser returns a connected user with the profile, or nil if there's none.
+func findUser(pro model.Profile) *User {
+	usermtx.Lock()
+	defer usermtx.Unlock()
+
+	for _, v := range users {
+		cl := v.Client()
+		if cl == nil || v.Profile.ID != pro.ID || v.Profile.Platform != pro.Platform {
+			continue
+		}
+
+		if cl.Connected() {
+			return v
+		}
+	}
+
+	return nil
+}
+
+// Resume recreates the adjourned game with id. The opponent needs to be online.
+func (u *User) Resume(id string) error {
+	if !u.Valid() {
+		return game.ErrClientNil
+	}
+
+	var s *model.Snapshot
+	for _, v := range game.Adjourned(u.Profile) {
+		if v.ID == id {
+			s = &v
+			break
+		}
+	}
+	if s == nil {
+		return game.ErrAdjournedNil
+	}
+
+	p1 := s.P1.ID == u.Profile.ID && s.P1.Platform == u.Profile.Platform
+	pro := s.P1
+	if p1 {
+		pro = s.P2
+	}
+
+	vs := findUser(pro)
+	if vs == nil || vs == u {
+		return ErrInvalidResume
+	}
+
+	u1, u2 := vs, u
+	if p1 {
+		u1, u2 = u, vs
+	}
+
+	c1, c2 := u1.freeClient(), u2.freeClient()
+	g, err := game.Resume(id, c1, c2)
+	if g != nil {
+		u1.addGame(id, c1)
+		u2.addGame(id, c2)
+		watchGame(u1, u2, g)
+	}
+
+	return err
+}
+
+// AdjournedHandler returns the adjourned games of the user.
+func AdjournedHandler(w http.ResponseWriter, r *http.Request) {
+	u, err := GetUser(r)
+	if err != nil {
+		RespondError(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	sl := []model.Watchable{}
+	for _, s := range game.Adjourned(u.Profile) {
+		sl = append(sl, model.Watchable{
+			ID:  s.ID,
+			P1:  s.P1,
+			P2:  s.P2,
+			Brd: s.Brd,
+		})
+	}
+
+	RespondJSON(w, http.StatusOK, sl)
+}
+
+// ResumeHandler resumes the adjourned game with the id in model.Generic.
+func ResumeHandler(w http.ResponseWriter, r *http.Request) {
+	u, err := GetUser(r)
+	if err != nil {
+		RespondError(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	gen := model.Generic{}
+	err = BindJSON(r, &gen)
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = u.Resume(gen.ID)
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, nil)
+}
@@ -0,0 +1,56 @@
+package rest
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/toms1441/chess-server/internal/game"
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+func TestUserResume(t *testing.T) {
+	u1, ch1 := newDrainedUser(t)
+	u2, ch2 := newDrainedUser(t)
+	defer u1.Delete()
+	defer u2.Delete()
+
+	g, err := startGame(u1, u2, model.GameOptions{})
+	if err != nil {
+		t.Fatalf("startGame: %s", err.Error())
+	}
+	id := g.ID()
+
+	err = u1.Client().Do(model.Order{ID: model.OrAdjourn})
+	if err != nil {
+		t.Fatalf("cl.Do: %s", err.Error())
+	}
+	waitFor(t, ch2, model.OrAdjourn)
+
+	body, _ := json.Marshal(model.ReplyOrder{Accept: true})
+	err = u2.Client().Do(model.Order{ID: model.OrAdjournReply, Data: body})
+	if err != nil {
+		t.Fatalf("cl.Do: %s", err.Error())
+	}
+	waitFor(t, ch1, model.OrDone)
+
+	err = u2.Resume("invalid")
+	if err != game.ErrAdjournedNil {
+		t.Fatalf("u.Resume: want: %v - have: %v", game.ErrAdjournedNil, err)
+	}
+
+	err = u2.Resume(id)
+	if err != nil {
+		t.Fatalf("u.Resume: %s", err.Error())
+	}
+
+	waitFor(t, ch1, model.OrGame)
+	waitFor(t, ch2, model.OrGame)
+
+	x := u1.Client().Game()
+	if x == nil || x != u2.Client().Game() || x.ID() != id {
+		t.Fatalf("resume does not recreate the game")
+	}
+	if !u1.Client().P1() {
+		t.Fatalf("resume does not keep the colors")
+	}
+}
@@ -0,0 +1,240 @@
+package rest
+
+import (
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/thanhpk/randstr"
+	"github.com/toms1441/chess-server/internal/game"
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+// challenge.go contains open challenges, which are shared as a link with players that aren't online yet. Whoever opens the link and logs in plays the challenger.
+
+// codeLength is the length of a challenge's code.
+const codeLength = 8
+
+var (
+	// ChallengeLifespan is how long an open challenge could be accepted.
+	ChallengeLifespan = time.Hour * 24
+	// ChallengeURL is prepended to the code of a challenge to get its link.
+	ChallengeURL = "/challenge/"
+)
+
+var challenges = map[string]*model.OpenChallenge{}
+var challengemtx sync.Mutex
+
+// Challenge creates an open challenge, which expires after lifespan. The challenger has to be online whenever it's accepted.
+func (u *User) Challenge(c model.Challenge, lifespan time.Duration) (model.OpenChallenge, error) {
+	if !u.Valid() {
+		return model.OpenChallenge{}, game.ErrClientNil
+	}
+
+	switch c.Color {
+	case "", model.ColorWhite, model.ColorBlack, model.ColorRandom:
+	default:
+		return model.OpenChallenge{}, ErrInvalidChallenge
+	}
+
+	if c.Variant == model.VariantStandard {
+		c.Variant = ""
+	}
+	if len(c.Variant) > 0 || c.TimeControl.Base < 0 || c.TimeControl.Increment < 0 || c.Days < 0 || c.Days > MaxDays {
+		return model.OpenChallenge{}, ErrInvalidChallenge
+	}
+
+	challengemtx.Lock()
+	// codes are random, so they could collide with a challenge that's still open
+	code := randstr.String(codeLength)
+	for _, ok := challenges[code]; ok; _, ok = challenges[code] {
+		code = randstr.String(codeLength)
+	}
+
+	oc := &model.OpenChallenge{
+		Challenge: c,
+		Code:      code,
+		URL:       ChallengeURL + code,
+		Profile:   u.Profile,
+		Expires:   time.Now().UTC().Add(lifespan),
+	}
+	challenges[code] = oc
+	challengemtx.Unlock()
+
+	time.AfterFunc(lifespan, func() {
+		challengemtx.Lock()
+		// the code could belong to another challenge, if this one got cancelled or accepted
+		if challenges[code] == oc {
+			delete(challenges, code)
+		}
+		challengemtx.Unlock()
+	})
+
+	return *oc, nil
+}
+
+// GetChallenge returns the open challenge with code.
+func GetChallenge(code string) (model.OpenChallenge, error) {
+	challengemtx.Lock()
+	defer challengemtx.Unlock()
+
+	oc, ok := challenges[code]
+	if !ok {
+		return model.OpenChallenge{}, ErrChallengeExpired
+	}
+
+	return *oc, nil
+}
+
+// AcceptChallenge starts a game between the user and the challenger, the challenge can't be accepted again.
+func (u *User) AcceptChallenge(code string) error {
+	if !u.Valid() {
+		return game.ErrClientNil
+	}
+
+	challengemtx.Lock()
+	oc, ok := challenges[code]
+	if !ok {
+		challengemtx.Unlock()
+		return ErrChallengeExpired
+	}
+
+	vs := userByProfile(oc.Profile)
+	if vs == u {
+		challengemtx.Unlock()
+		return ErrInvalidChallenge
+	}
+	if vs == nil || !vs.Valid() {
+		challengemtx.Unlock()
+		return game.ErrClientNil
+	}
+	if blocked(u.Profile, vs.Profile) {
+		challengemtx.Unlock()
+		return ErrBlocked
+	}
+
+	delete(challenges, code)
+	challengemtx.Unlock()
+
+	var p1 bool
+	switch oc.Color {
+	case model.ColorWhite:
+		p1 = true
+	case model.ColorBlack:
+		p1 = false
+	default:
+		p1 = rand.Intn(2) == 0
+	}
+
+	opts := model.GameOptions{
+		TimeControl: oc.TimeControl,
+		Days:        oc.Days,
+	}
+
+	var err error
+	if p1 {
+		_, err = startGame(vs, u, opts)
+	} else {
+		_, err = startGame(u, vs, opts)
+	}
+
+	if err != nil {
+		// the challenge could be accepted by someone else, unless it expired meanwhile
+		challengemtx.Lock()
+		if _, ok := challenges[code]; !ok && time.Now().Before(oc.Expires) {
+			challenges[code] = oc
+		}
+		challengemtx.Unlock()
+	}
+
+	return err
+}
+
+// CancelChallenge removes the user's open challenge with code.
+func (u *User) CancelChallenge(code string) error {
+	challengemtx.Lock()
+	defer challengemtx.Unlock()
+
+	oc, ok := challenges[code]
+	if !ok {
+		return ErrChallengeExpired
+	}
+
+	if oc.Profile.ID != u.Profile.ID || oc.Profile.Platform != u.Profile.Platform {
+		return ErrInvalidChallenge
+	}
+
+	delete(challenges, code)
+	return nil
+}
+
+// ChallengeHandler creates an open challenge with the options in the body, and returns it with its code and link.
+func ChallengeHandler(w http.ResponseWriter, r *http.Request) {
+	u, err := GetUser(r)
+	if err != nil {
+		RespondError(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	c := model.Challenge{}
+	err = BindJSON(r, &c)
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	oc, err := u.Challenge(c, ChallengeLifespan)
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, oc)
+}
+
+// GetChallengeHandler returns the open challenge with the code in the path. It doesn't require the user to be logged in, so that the challenge could be shown before they do.
+func GetChallengeHandler(w http.ResponseWriter, r *http.Request) {
+	oc, err := GetChallenge(mux.Vars(r)["code"])
+	if err != nil {
+		RespondError(w, http.StatusNotFound, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, oc)
+}
+
+// AcceptChallengeHandler accepts the open challenge with the code in the path.
+func AcceptChallengeHandler(w http.ResponseWriter, r *http.Request) {
+	u, err := GetUser(r)
+	if err != nil {
+		RespondError(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	err = u.AcceptChallenge(mux.Vars(r)["code"])
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, nil)
+}
+
+// CancelChallengeHandler cancels the user's open challenge with the code in the path.
+func CancelChallengeHandler(w http.ResponseWriter, r *http.Request) {
+	u, err := GetUser(r)
+	if err != nil {
+		RespondError(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	err = u.CancelChallenge(mux.Vars(r)["code"])
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, nil)
+}
@@ -0,0 +1,112 @@
+package rest
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+func TestUserChallenge(t *testing.T) {
+	u1, ch1 := newDrainedUser(t)
+	u2, ch2 := newDrainedUser(t)
+	defer u1.Delete()
+	defer u2.Delete()
+
+	_, err := u1.Challenge(model.Challenge{Variant: "crazyhouse"}, ChallengeLifespan)
+	if err != ErrInvalidChallenge {
+		t.Fatalf("u.Challenge: want: %v - have: %v", ErrInvalidChallenge, err)
+	}
+
+	blitz := model.TimeControl{Base: 180, Increment: 2}
+	oc, err := u1.Challenge(model.Challenge{TimeControl: blitz, Color: model.ColorBlack}, ChallengeLifespan)
+	if err != nil {
+		t.Fatalf("u.Challenge: %s", err.Error())
+	}
+	if len(oc.Code) != codeLength || !strings.HasSuffix(oc.URL, oc.Code) || oc.Profile != u1.Profile {
+		t.Fatalf("unexpected challenge: %+v", oc)
+	}
+
+	if _, err := GetChallenge(oc.Code); err != nil {
+		t.Fatalf("GetChallenge: %s", err.Error())
+	}
+
+	err = u1.AcceptChallenge(oc.Code)
+	if err != ErrInvalidChallenge {
+		t.Fatalf("u.AcceptChallenge: want: %v - have: %v", ErrInvalidChallenge, err)
+	}
+
+	err = u2.AcceptChallenge(oc.Code)
+	if err != nil {
+		t.Fatalf("u.AcceptChallenge: %s", err.Error())
+	}
+
+	waitFor(t, ch1, model.OrGame)
+	waitFor(t, ch2, model.OrGame)
+
+	g := u1.Client().Game()
+	if g == nil || g != u2.Client().Game() || g.Options().TimeControl != blitz || u1.Client().P1() {
+		t.Fatalf("challenge does not start the game with its options")
+	}
+
+	err = u2.AcceptChallenge(oc.Code)
+	if err != ErrChallengeExpired {
+		t.Fatalf("u.AcceptChallenge: want: %v - have: %v", ErrChallengeExpired, err)
+	}
+
+	// expired and cancelled challenges
+	oc, err = u1.Challenge(model.Challenge{}, time.Millisecond*10)
+	if err != nil {
+		t.Fatalf("u.Challenge: %s", err.Error())
+	}
+	<-time.After(time.Millisecond * 20)
+	if _, err := GetChallenge(oc.Code); err != ErrChallengeExpired {
+		t.Fatalf("GetChallenge: want: %v - have: %v", ErrChallengeExpired, err)
+	}
+
+	oc, err = u1.Challenge(model.Challenge{}, ChallengeLifespan)
+	if err != nil {
+		t.Fatalf("u.Challenge: %s", err.Error())
+	}
+	if err := u2.CancelChallenge(oc.Code); err != ErrInvalidChallenge {
+		t.Fatalf("u.CancelChallenge: want: %v - have: %v", ErrInvalidChallenge, err)
+	}
+	if err := u1.CancelChallenge(oc.Code); err != nil {
+		t.Fatalf("u.CancelChallenge: %s", err.Error())
+	}
+	if _, err := GetChallenge(oc.Code); err != ErrChallengeExpired {
+		t.Fatalf("GetChallenge: want: %v - have: %v", ErrChallengeExpired, err)
+	}
+}
+
+func TestUserAcceptChallengeFailed(t *testing.T) {
+	u1, _ := newDrainedUser(t)
+	u2, _ := newDrainedUser(t)
+	defer u1.Delete()
+	defer u2.Delete()
+
+	oc, err := u1.Challenge(model.Challenge{}, ChallengeLifespan)
+	if err != nil {
+		t.Fatalf("u.Challenge: %s", err.Error())
+	}
+
+	// the game can't be created without a writer
+	cl := u2.Client()
+	w := cl.W
+	cl.W = nil
+	err = u2.AcceptChallenge(oc.Code)
+	cl.W = w
+	if err == nil {
+		t.Fatalf("u.AcceptChallenge: game is started without a writer")
+	}
+
+	if _, err := GetChallenge(oc.Code); err != nil {
+		t.Fatalf("challenge is gone after failing to start the game: %s", err.Error())
+	}
+
+	err = u2.AcceptChallenge(oc.Code)
+	if err != nil {
+		t.Fatalf("u.AcceptChallenge: %s", err.Error())
+	}
+}
@@ -9,19 +9,13 @@ import (
 	"github.com/toms1441/chess-server/internal/model"
 )
 
+// CmdHandler executes a command in the user's game with ?game=, or in the game of the user's main client if it's empty.
 func CmdHandler(w http.ResponseWriter, r *http.Request) {
 	u, err := GetUser(r)
 	if err != nil {
 		RespondError(w, http.StatusUnauthorized, err)
 		return
 	}
-	cl := u.Client()
-
-	g := cl.Game()
-	if g == nil {
-		RespondError(w, http.StatusNotFound, game.ErrGameNil)
-		return
-	}
 
 	cmd := model.Order{}
 	err = BindJSON(r, &cmd)
@@ -30,12 +24,42 @@ func CmdHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// rematches are asked for after the game ends, so there's no game to run them
+	if cmd.ID == model.OrRematch {
+		err = u.Rematch()
+		if err != nil {
+			RespondError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		RespondJSON(w, http.StatusOK, map[string]interface{}{
+			"success": true,
+		})
+		return
+	}
+
+	cl := u.clientFor(r.URL.Query().Get("game"))
+	if cl == nil {
+		RespondError(w, http.StatusNotFound, game.ErrGameNil)
+		return
+	}
+
+	g := cl.Game()
+	if g == nil {
+		RespondError(w, http.StatusNotFound, game.ErrGameNil)
+		return
+	}
+
 	err = cl.Do(cmd)
 	if err != nil {
 		RespondError(w, http.StatusBadRequest, err)
 		return
 	}
 
+	if g.Correspondence() {
+		SaveCorrespondence()
+	}
+
 	RespondJSON(w, http.StatusOK, map[string]interface{}{
 		"success": true,
 	})
@@ -43,6 +67,7 @@ func CmdHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 // since this is a specific handler and not via CmdHandler then there is no need to parse order.Order.
+// The game is chosen via ?game=, the same as CmdHandler.
 func PossibHandler(w http.ResponseWriter, r *http.Request) {
 	u, err := GetUser(r)
 	if err != nil {
@@ -50,7 +75,7 @@ func PossibHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cl := u.Client()
+	cl := u.clientFor(r.URL.Query().Get("game"))
 	if cl == nil {
 		RespondError(w, http.StatusUnauthorized, game.ErrClientNil)
 		return
@@ -0,0 +1,153 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"sync"
+
+	"github.com/toms1441/chess-server/internal/game"
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+// correspondence.go contains the correspondence games, which are played with days per move and persisted between moves.
+
+// MaxDays is the most days per move a correspondence game could have.
+const MaxDays = 14
+
+// CorrespondenceFile is where correspondence games are saved, empty means they're only kept in memory.
+var CorrespondenceFile string
+
+var corrmtx sync.Mutex
+
+// corrObserver saves the correspondence games whenever one starts or ends.
+type corrObserver struct {
+	game.NopObserver
+}
+
+func (corrObserver) OnStart(g *game.Game) {
+	if g.Correspondence() {
+		go SaveCorrespondence()
+	}
+}
+
+func (corrObserver) OnEnd(g *game.Game, ev game.EndEvent) {
+	if g.Correspondence() {
+		go SaveCorrespondence()
+	}
+}
+
+func init() {
+	game.Observe(corrObserver{})
+}
+
+// Correspondence returns the user's correspondence games in progress.
+func (u *User) Correspondence() []*game.Game {
+	sl := []*game.Game{}
+	for _, g := range u.Games() {
+		if g.Correspondence() {
+			sl = append(sl, g)
+		}
+	}
+
+	return sl
+}
+
+// attach binds the user's connection to every correspondence game they play, and sends the state of each game.
+func (u *User) attach() {
+	cl := u.Client()
+	if cl == nil {
+		return
+	}
+
+	for _, g := range game.ByPlayer(u.Profile) {
+		if !g.Correspondence() {
+			continue
+		}
+
+		for _, p1 := range []bool{true, false} {
+			pc := g.Player(p1)
+			// the client could be attached to another connection
+			if pc == nil || pc.Profile.ID != u.Profile.ID || pc.Profile.Platform != u.Profile.Platform || pc.Connected() {
+				continue
+			}
+
+			if pc.Reconnect(cl.W, opponentOf(g, pc)) == nil {
+				u.addGame(g.ID(), pc)
+			}
+		}
+	}
+}
+
+// SaveCorrespondence writes every correspondence game in progress to CorrespondenceFile. It's called after every move, and whenever a correspondence game starts or ends.
+func SaveCorrespondence() error {
+	if len(CorrespondenceFile) == 0 {
+		return nil
+	}
+
+	corrmtx.Lock()
+	defer corrmtx.Unlock()
+
+	sl := []model.Snapshot{}
+	for _, g := range game.Games() {
+		if g.Correspondence() && !g.Done() {
+			sl = append(sl, g.Snapshot())
+		}
+	}
+
+	return writeFile(CorrespondenceFile, sl)
+}
+
+// LoadCorrespondence restores the correspondence games saved in path, and saves them to path from now on. Players are attached to their games whenever they connect.
+// The time the server is down isn't counted against the side to move. It's not an error if path doesn't exist.
+func LoadCorrespondence(path string) (int, error) {
+	CorrespondenceFile = path
+
+	body, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	sl := []model.Snapshot{}
+	err = json.Unmarshal(body, &sl)
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, s := range sl {
+		g, err := game.Restore(s)
+		if err != nil {
+			continue
+		}
+
+		// the players are attached whenever they connect, so there's no watchGame
+		go func(g *game.Game, s model.Snapshot) {
+			<-g.ListenForDone()
+			finish(g, s.P1, s.P2)
+		}(g, s)
+		n++
+	}
+
+	return n, nil
+}
+
+// CorrespondenceHandler returns the user's correspondence games in progress.
+func CorrespondenceHandler(w http.ResponseWriter, r *http.Request) {
+	u, err := GetUser(r)
+	if err != nil {
+		RespondError(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	gs := u.Correspondence()
+	sl := make([]model.Watchable, 0, len(gs))
+	for _, g := range gs {
+		sl = append(sl, gameModel(g))
+	}
+
+	RespondJSON(w, http.StatusOK, sl)
+}
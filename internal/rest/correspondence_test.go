@@ -0,0 +1,90 @@
+package rest
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/toms1441/chess-server/internal/board"
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+func TestUserCorrespondence(t *testing.T) {
+	u1, _ := newDrainedUser(t)
+	u2, ch2 := newDrainedUser(t)
+	defer u1.Delete()
+
+	CorrespondenceFile = filepath.Join(t.TempDir(), "correspondence.json")
+	defer func() {
+		CorrespondenceFile = ""
+	}()
+
+	err := u1.Invite(model.InviteOrder{Profile: u2.Profile, Days: MaxDays + 1}, InviteLifespan)
+	if err != ErrInvalidInvite {
+		t.Fatalf("u.Invite: want: %v - have: %v", ErrInvalidInvite, err)
+	}
+
+	err = u1.Invite(model.InviteOrder{Profile: u2.Profile, Color: model.ColorWhite, Days: 3}, InviteLifespan)
+	if err != nil {
+		t.Fatalf("u.Invite: %s", err.Error())
+	}
+	waitFor(t, ch2, model.OrInvite)
+
+	err = u2.AcceptInvite(u1.Profile.GetInviteID())
+	if err != nil {
+		t.Fatalf("u.AcceptInvite: %s", err.Error())
+	}
+
+	gs := u1.Correspondence()
+	if len(gs) != 1 || u1.Client().Game() != nil {
+		t.Fatalf("correspondence game takes the user's live game")
+	}
+	g := gs[0]
+
+	// the opponent goes offline
+	pro := u2.Profile
+	u2.Delete()
+
+	body, _ := json.Marshal(model.MoveOrder{ID: 20, Dst: board.Point{X: 4, Y: 4}})
+	err = u1.clientFor(g.ID()).Do(model.Order{ID: model.OrMove, Data: body})
+	if err != nil {
+		t.Fatalf("cl.Do: %s", err.Error())
+	}
+
+	err = SaveCorrespondence()
+	if err != nil {
+		t.Fatalf("SaveCorrespondence: %s", err.Error())
+	}
+
+	saved, _ := os.ReadFile(CorrespondenceFile)
+	sl := []model.Snapshot{}
+	json.Unmarshal(saved, &sl)
+	if len(sl) != 1 || sl[0].ID != g.ID() || len(sl[0].History) != 1 {
+		t.Fatalf("correspondence game is not saved after the move")
+	}
+
+	// the opponent comes back
+	rd, wr := io.Pipe()
+	ch := drain(rd)
+	u3, err := AddClient(pro, wr)
+	if err != nil {
+		t.Fatalf("AddClient: %s", err.Error())
+	}
+	defer u3.Delete()
+	u3.attach()
+
+	o := waitFor(t, ch, model.OrHistory)
+	history := model.HistoryOrder{}
+	json.Unmarshal(o.Data, &history)
+	if len(history.History) != 1 {
+		t.Fatalf("attached player does not receive the move played while offline")
+	}
+
+	if u3.clientFor(g.ID()) != g.Player(false) {
+		t.Fatalf("player is not attached to their correspondence game")
+	}
+
+	g.Player(false).LeaveGame()
+}
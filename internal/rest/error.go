@@ -3,7 +3,16 @@ package rest
 import "errors"
 
 var (
-	ErrInvalidInvite = errors.New("invalid invite")
-	ErrInviteRate    = errors.New("already invited player. please wait")
-	ErrInternal      = errors.New("internal error, please report to the developer")
+	ErrInvalidInvite    = errors.New("invalid invite")
+	ErrInviteRate       = errors.New("already invited player. please wait")
+	ErrInvalidRematch   = errors.New("no rematch is available")
+	ErrInvalidReconnect = errors.New("cannot reconnect, no game is waiting for you")
+	ErrInvalidResume    = errors.New("cannot resume, the opponent is offline")
+	ErrInvalidSeek      = errors.New("invalid seek")
+	ErrInvalidChallenge = errors.New("invalid challenge")
+	ErrChallengeExpired = errors.New("the challenge does not exist or has expired")
+	ErrBlocked          = errors.New("the player is not available")
+	ErrInvalidCategory  = errors.New("invalid rating category")
+	ErrLeaderboardSize  = errors.New("invalid leaderboard size")
+	ErrInternal         = errors.New("internal error, please report to the developer")
 )
@@ -0,0 +1,112 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/toms1441/chess-server/internal/friends"
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+// friends.go contains the friends and the blocked players of users. Blocked players can't invite, challenge or be paired with the user, nor see them in the available users.
+
+// blocked returns true whenever either player blocked the other.
+func blocked(a, b model.Profile) bool {
+	return friends.Blocks(a, b) || friends.Blocks(b, a)
+}
+
+// notifyFriends sends the update with id to every online user that has pro as a friend.
+func notifyFriends(pro model.Profile, id uint8) {
+	body, err := json.Marshal(model.FriendOrder{
+		Profile: pro,
+	})
+	if err != nil {
+		return
+	}
+
+	send, err := json.Marshal(model.Order{
+		ID:   id,
+		Data: body,
+	})
+	if err != nil {
+		return
+	}
+
+	for _, f := range friends.Followers(pro) {
+		if vs := userByProfile(f); vs != nil {
+			vs.conns.Write(send)
+		}
+	}
+}
+
+// Friends returns the user's friends along with whether they're online, and the players they blocked.
+func (u *User) Friends() model.Friends {
+	fs := model.Friends{
+		Friends: []model.FriendStatus{},
+		Blocked: friends.Blocked(u.Profile),
+	}
+
+	for _, f := range friends.Friends(u.Profile) {
+		fs.Friends = append(fs.Friends, model.FriendStatus{
+			Profile: f,
+			Online:  userByProfile(f) != nil,
+		})
+	}
+
+	return fs
+}
+
+// FriendsHandler returns the user's friends and blocked players.
+func FriendsHandler(w http.ResponseWriter, r *http.Request) {
+	u, err := GetUser(r)
+	if err != nil {
+		RespondError(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, u.Friends())
+}
+
+// friendRequest calls fn with the user's profile and the profile in the body.
+func friendRequest(w http.ResponseWriter, r *http.Request, fn func(pro, vs model.Profile) error) {
+	u, err := GetUser(r)
+	if err != nil {
+		RespondError(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	fo := model.FriendOrder{}
+	err = BindJSON(r, &fo)
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = fn(u.Profile, fo.Profile)
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, nil)
+}
+
+// FollowHandler adds the profile in the body to the user's friends.
+func FollowHandler(w http.ResponseWriter, r *http.Request) {
+	friendRequest(w, r, friends.Follow)
+}
+
+// UnfollowHandler removes the profile in the body from the user's friends.
+func UnfollowHandler(w http.ResponseWriter, r *http.Request) {
+	friendRequest(w, r, friends.Unfollow)
+}
+
+// BlockHandler blocks the profile in the body.
+func BlockHandler(w http.ResponseWriter, r *http.Request) {
+	friendRequest(w, r, friends.Block)
+}
+
+// UnblockHandler unblocks the profile in the body.
+func UnblockHandler(w http.ResponseWriter, r *http.Request) {
+	friendRequest(w, r, friends.Unblock)
+}
@@ -0,0 +1,154 @@
+package rest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/toms1441/chess-server/internal/friends"
+	"github.com/toms1441/chess-server/internal/model"
+	"github.com/toms1441/chess-server/internal/model/local"
+)
+
+func TestUserFriends(t *testing.T) {
+	friends.Reset()
+	defer friends.Reset()
+
+	u1, ch1 := newDrainedUser(t)
+	u2, _ := newDrainedUser(t)
+	defer u1.Delete()
+
+	err := friends.Follow(u1.Profile, u2.Profile)
+	if err != nil {
+		t.Fatalf("friends.Follow: %s", err.Error())
+	}
+
+	fs := u1.Friends()
+	if len(fs.Friends) != 1 || fs.Friends[0].Profile != u2.Profile || !fs.Friends[0].Online {
+		t.Fatalf("unexpected friends: %+v", fs)
+	}
+
+	avali := func(u *User, query string) []model.Profile {
+		req := httptest.NewRequest("GET", "/"+query, nil)
+		req.Header.Add("Authorization", u.Token)
+
+		resp := httptest.NewRecorder()
+		GetAvaliableUsersHandler(resp, req)
+		if resp.Code != http.StatusOK {
+			t.Fatalf("status: %d", resp.Code)
+		}
+
+		sl := []model.Profile{}
+		json.Unmarshal(resp.Body.Bytes(), &sl)
+		return sl
+	}
+
+	if sl := avali(u1, "?friends=true"); len(sl) != 1 || sl[0] != u2.Profile {
+		t.Fatalf("unexpected friends: %v", sl)
+	}
+	if sl := avali(u2, "?friends=true"); len(sl) != 0 {
+		t.Fatalf("friends aren't one way: %v", sl)
+	}
+
+	// u1 gets notified whenever u2 leaves or connects
+	pro := u2.Profile
+	u2.Delete()
+	o := waitFor(t, ch1, model.OrFriendOffline)
+
+	fo := model.FriendOrder{}
+	json.Unmarshal(o.Data, &fo)
+	if fo.Profile != pro {
+		t.Fatalf("unexpected friend: %+v", fo.Profile)
+	}
+
+	pro = local.NewUser()
+	err = friends.Follow(u1.Profile, pro)
+	if err != nil {
+		t.Fatalf("friends.Follow: %s", err.Error())
+	}
+
+	rd, wr := io.Pipe()
+	u3, err := AddClient(pro, wr)
+	if err != nil {
+		t.Fatalf("AddClient: %s", err.Error())
+	}
+	drain(rd)
+	defer u3.Delete()
+
+	// u2's own update could arrive late, as it's followed right after connecting
+	for fo.Profile != pro {
+		o = waitFor(t, ch1, model.OrFriendOnline)
+		json.Unmarshal(o.Data, &fo)
+	}
+
+	u2, ch2 := newDrainedUser(t)
+	defer u2.Delete()
+
+	// blocked players can't invite, and don't see each other
+	err = friends.Block(u2.Profile, u1.Profile)
+	if err != nil {
+		t.Fatalf("friends.Block: %s", err.Error())
+	}
+
+	err = u1.Invite(model.InviteOrder{Profile: u2.Profile}, InviteLifespan)
+	if err != ErrBlocked {
+		t.Fatalf("u.Invite: want: %v - have: %v", ErrBlocked, err)
+	}
+	err = u2.Invite(model.InviteOrder{Profile: u1.Profile}, InviteLifespan)
+	if err != ErrBlocked {
+		t.Fatalf("u.Invite: want: %v - have: %v", ErrBlocked, err)
+	}
+
+	for _, v := range avali(u1, "") {
+		if v == u2.Profile {
+			t.Fatalf("blocked player is available")
+		}
+	}
+
+	select {
+	case o := <-ch2:
+		if o.ID == model.OrInvite {
+			t.Fatalf("blocked player got invited")
+		}
+	default:
+	}
+}
+
+func TestUserAcceptInviteBlocked(t *testing.T) {
+	friends.Reset()
+	defer friends.Reset()
+
+	u1, ch1 := newDrainedUser(t)
+	u2, _ := newDrainedUser(t)
+	defer u1.Delete()
+	defer u2.Delete()
+
+	err := u1.Invite(model.InviteOrder{Profile: u2.Profile}, InviteLifespan)
+	if err != nil {
+		t.Fatalf("u.Invite: %s", err.Error())
+	}
+
+	err = friends.Block(u2.Profile, u1.Profile)
+	if err != nil {
+		t.Fatalf("friends.Block: %s", err.Error())
+	}
+
+	err = u2.AcceptInvite(u1.Profile.GetInviteID())
+	if err != ErrBlocked {
+		t.Fatalf("u.AcceptInvite: want: %v - have: %v", ErrBlocked, err)
+	}
+
+	if u1.Client().Game() != nil || u2.Client().Game() != nil {
+		t.Fatalf("game is started with a blocked player")
+	}
+
+	select {
+	case o := <-ch1:
+		if o.ID == model.OrGame {
+			t.Fatalf("blocked player got a game")
+		}
+	default:
+	}
+}
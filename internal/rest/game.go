@@ -0,0 +1,73 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/toms1441/chess-server/internal/game"
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+// gameModel returns the game's players, board and opening.
+func gameModel(g *game.Game) model.Watchable {
+	m := model.Watchable{
+		ID:      g.ID(),
+		Brd:     g.Board(),
+		Opening: g.Opening(),
+	}
+
+	if cl := g.Player(true); cl != nil {
+		m.P1 = cl.Profile
+	}
+	if cl := g.Player(false); cl != nil {
+		m.P2 = cl.Profile
+	}
+
+	return m
+}
+
+// GamesHandler returns every game in progress. Games could be filtered by player via ?platform= and ?id=
+func GamesHandler(w http.ResponseWriter, r *http.Request) {
+	_, err := GetUser(r)
+	if err != nil {
+		RespondError(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	var gs []*game.Game
+
+	query := r.URL.Query()
+	if id := query.Get("id"); len(id) > 0 {
+		gs = game.ByPlayer(model.Profile{
+			ID:       id,
+			Platform: query.Get("platform"),
+		})
+	} else {
+		gs = game.Games()
+	}
+
+	sl := make([]model.Watchable, 0, len(gs))
+	for _, g := range gs {
+		m := gameModel(g)
+		m.Brd = nil
+		sl = append(sl, m)
+	}
+
+	RespondJSON(w, http.StatusOK, sl)
+}
+
+// GameHandler returns the game with ?id=
+func GameHandler(w http.ResponseWriter, r *http.Request) {
+	_, err := GetUser(r)
+	if err != nil {
+		RespondError(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	g, err := game.Get(r.URL.Query().Get("id"))
+	if err != nil {
+		RespondError(w, http.StatusNotFound, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, gameModel(g))
+}
@@ -0,0 +1,45 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+func TestGamesHandler(t *testing.T) {
+	u1, _ := newDrainedUser(t)
+	u2, _ := newDrainedUser(t)
+	defer u1.Delete()
+	defer u2.Delete()
+
+	g, err := startGame(u1, u2, model.GameOptions{})
+	if err != nil {
+		t.Fatalf("startGame: %s", err.Error())
+	}
+
+	query := url.Values{}
+	query.Set("id", u2.Profile.ID)
+	query.Set("platform", u2.Profile.Platform)
+	req, _ := http.NewRequest("GET", "/games?"+query.Encode(), nil)
+	req.Header.Set("Authorization", "Bearer "+u1.Token)
+	resp := httptest.NewRecorder()
+	http.HandlerFunc(GamesHandler).ServeHTTP(resp, req)
+
+	sl := []model.Watchable{}
+	json.Unmarshal(resp.Body.Bytes(), &sl)
+	if len(sl) != 1 || sl[0].ID != g.ID() || sl[0].P1 != u1.Profile || sl[0].P2 != u2.Profile {
+		t.Fatalf("GamesHandler: does not find the player's game: %s", resp.Body.String())
+	}
+
+	req, _ = http.NewRequest("GET", "/game?id="+url.QueryEscape(g.ID()), nil)
+	req.Header.Set("Authorization", "Bearer "+u1.Token)
+	resp = httptest.NewRecorder()
+	http.HandlerFunc(GameHandler).ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("GameHandler: want: %d - have: %d", http.StatusOK, resp.Code)
+	}
+}
@@ -0,0 +1,157 @@
+package rest
+
+import (
+	"github.com/toms1441/chess-server/internal/game"
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+// games.go contains the games a user plays or spectates besides the game of their main client. Each game has its own client, which writes to the user's connection.
+
+// newClient returns a client for another game, which writes to the user's connections. The main client's writer isn't used, as it discards updates while it's disconnected.
+func (u *User) newClient() *game.Client {
+	return &game.Client{
+		W:       u.conns,
+		Profile: u.Profile,
+	}
+}
+
+// freeClient returns the user's main client if it's not in a game, otherwise a new client.
+func (u *User) freeClient() *game.Client {
+	cl := u.Client()
+	if cl.Game() == nil {
+		return cl
+	}
+
+	return u.newClient()
+}
+
+// addGame adds cl to the user's games, unless it's the user's main client.
+func (u *User) addGame(id string, cl *game.Client) {
+	if cl == u.Client() {
+		return
+	}
+
+	u.mtx.Lock()
+	if u.games == nil {
+		u.games = map[string]*game.Client{}
+	}
+	u.games[id] = cl
+	u.mtx.Unlock()
+}
+
+func (u *User) rmGame(id string) {
+	u.mtx.Lock()
+	delete(u.games, id)
+	u.mtx.Unlock()
+}
+
+// clients returns the clients of the user's games besides their main client, ended games are removed.
+func (u *User) clients() map[string]*game.Client {
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+
+	m := map[string]*game.Client{}
+	for id, cl := range u.games {
+		// spectators keep the game after it ends
+		if g := cl.Game(); g == nil || g.Done() {
+			delete(u.games, id)
+			continue
+		}
+
+		m[id] = cl
+	}
+
+	return m
+}
+
+// client returns the user's client in the game with id, or nil if the user doesn't play or spectate it.
+func (u *User) client(id string) *game.Client {
+	if cl := u.Client(); cl != nil {
+		if g := cl.Game(); g != nil && g.ID() == id {
+			return cl
+		}
+	}
+
+	return u.clients()[id]
+}
+
+// clientFor returns the user's client in the game with id, an empty id means the game of the user's main client.
+func (u *User) clientFor(id string) *game.Client {
+	if len(id) == 0 {
+		return u.Client()
+	}
+
+	return u.client(id)
+}
+
+// Games returns every game the user plays or spectates.
+func (u *User) Games() []*game.Game {
+	sl := []*game.Game{}
+	if cl := u.Client(); cl != nil {
+		if g := cl.Game(); g != nil {
+			sl = append(sl, g)
+		}
+	}
+
+	for _, cl := range u.clients() {
+		if g := cl.Game(); g != nil {
+			sl = append(sl, g)
+		}
+	}
+
+	return sl
+}
+
+// playing returns true whenever the user plays a live game, which they could reconnect to.
+func (u *User) playing() bool {
+	cls := []*game.Client{u.Client()}
+	for _, cl := range u.clients() {
+		cls = append(cls, cl)
+	}
+
+	for _, cl := range cls {
+		g := cl.Game()
+		if g != nil && g.IsPlayer(cl) && !g.Correspondence() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// detach is called whenever the user's connection closes. Spectated games are left, and correspondence games are disconnected as they keep going while the user is offline.
+func (u *User) detach() {
+	for id, cl := range u.clients() {
+		g := cl.Game()
+		if !g.IsPlayer(cl) {
+			g.RmSpectator(cl)
+			u.rmGame(id)
+		} else if g.Correspondence() {
+			cl.Disconnect()
+			u.rmGame(id)
+		}
+	}
+}
+
+// leave is called whenever the user gets deleted. Live games are forfeited, and the rest are detached.
+func (u *User) leave() {
+	u.detach()
+
+	for id, cl := range u.clients() {
+		cl.LeaveGame()
+		u.rmGame(id)
+	}
+}
+
+// opponentOf returns the profile of cl's opponent, or nil if cl is a spectator.
+func opponentOf(g *game.Game, cl *game.Client) *model.Profile {
+	if !g.IsPlayer(cl) {
+		return nil
+	}
+
+	if vs := g.Player(!cl.P1()); vs != nil {
+		return &vs.Profile
+	}
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/toms1441/chess-server/internal/board"
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+func TestUserGames(t *testing.T) {
+	u1, ch1 := newDrainedUser(t)
+	u2, _ := newDrainedUser(t)
+	u3, _ := newDrainedUser(t)
+	defer u1.Delete()
+	defer u2.Delete()
+	defer u3.Delete()
+
+	g1, err := startGame(u1, u2, model.GameOptions{})
+	if err != nil {
+		t.Fatalf("startGame: %s", err.Error())
+	}
+
+	g2, err := startGame(u1, u3, model.GameOptions{})
+	if err != nil {
+		t.Fatalf("startGame: %s", err.Error())
+	}
+
+	if u1.Client().Game() != g1 || u1.client(g2.ID()) == nil || len(u1.Games()) != 2 {
+		t.Fatalf("user does not play both games")
+	}
+
+	// move in the second game
+	body, _ := json.Marshal(model.MoveOrder{ID: 20, Dst: board.Point{X: 4, Y: 4}})
+	body, _ = json.Marshal(model.Order{ID: model.OrMove, Data: body})
+
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/?game="+g2.ID(), bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+u1.Token)
+	http.HandlerFunc(CmdHandler).ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("CmdHandler: %d: %s", resp.Code, resp.Body.String())
+	}
+
+	for {
+		o := waitFor(t, ch1, model.OrMove)
+		if o.Game == g2.ID() {
+			break
+		}
+	}
+
+	if len(g1.History()) != 0 || len(g2.History()) != 1 {
+		t.Fatalf("command is not addressed to the game with the id")
+	}
+
+	// u2 spectates the second game while playing the first one
+	body, _ = json.Marshal(model.Generic{ID: g2.ID()})
+	resp = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+u2.Token)
+	http.HandlerFunc(WatchableJoinHandler).ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("WatchableJoinHandler: %d: %s", resp.Code, resp.Body.String())
+	}
+
+	if u2.Client().Game() != g1 || u2.client(g2.ID()) == nil {
+		t.Fatalf("user does not spectate while playing")
+	}
+
+	resp = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/?game="+g2.ID(), nil)
+	req.Header.Set("Authorization", "Bearer "+u2.Token)
+	http.HandlerFunc(WatchableLeaveHandler).ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK || u2.client(g2.ID()) != nil {
+		t.Fatalf("WatchableLeaveHandler: %d: %s", resp.Code, resp.Body.String())
+	}
+}
+
+func TestUserNewClientDisconnected(t *testing.T) {
+	u1, ch1 := newDrainedUser(t)
+	u2, _ := newDrainedUser(t)
+	defer u1.Delete()
+	defer u2.Delete()
+
+	_, err := startGame(u1, u2, model.GameOptions{})
+	if err != nil {
+		t.Fatalf("startGame: %s", err.Error())
+	}
+
+	// the main client discards updates while it's disconnected, other games still write to the user's connections
+	u1.Client().Disconnect()
+
+	g, err := startGame(u1, u2, model.GameOptions{Days: 3})
+	if err != nil {
+		t.Fatalf("startGame: %s", err.Error())
+	}
+
+	for {
+		o := waitFor(t, ch1, model.OrGame)
+		gm := model.GameOrder{}
+		json.Unmarshal(o.Data, &gm)
+		if gm.ID == g.ID() {
+			break
+		}
+	}
+}
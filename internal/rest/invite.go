@@ -3,31 +3,96 @@ package rest
 import (
 	"encoding/json"
 	"fmt"
+	"math/rand"
 	"net/http"
 	"time"
 
+	"github.com/toms1441/chess-server/internal/board"
 	"github.com/toms1441/chess-server/internal/game"
 	"github.com/toms1441/chess-server/internal/model"
 )
 
+// InviteInterval is the minimum time between two invites from the same user, cancelled or not.
+var InviteInterval = time.Second * 5
+
+// pendingInvite is an invite from vs, which could be accepted before it expires.
+type pendingInvite struct {
+	vs      *User
+	inv     model.InviteOrder
+	expires time.Time
+	// timer expires the invite, it's stopped whenever the invite is taken
+	timer *time.Timer
+}
+
+// options returns the game options of the invite, and if the inviter plays white.
+func (p *pendingInvite) options() (model.GameOptions, bool) {
+	var p1 bool
+	switch p.inv.Color {
+	case model.ColorWhite:
+		p1 = true
+	case model.ColorBlack:
+		p1 = false
+	default:
+		p1 = rand.Intn(2) == 0
+	}
+
+	opts := model.GameOptions{
+		FEN:         p.inv.FEN,
+		Days:        p.inv.Days,
+		TimeControl: p.inv.TimeControl,
+		Rated:       p.inv.Rated,
+	}
+
+	if h := p.inv.Handicap; h != nil {
+		opts.Handicap = &model.Handicap{
+			Odds: h.Odds,
+			Time: h.Time,
+			P1:   p1,
+		}
+	}
+
+	return opts, p1
+}
+
 func (u *User) Invite(inv model.InviteOrder, lifespan time.Duration) error {
 	// make sure panic don't happen
 	if !u.Valid() {
 		return game.ErrClientNil
 	}
-	if u.Client().Game() != nil {
-		return game.ErrGameIsNotNil
+	if inv.Days < 0 || inv.Days > MaxDays || inv.TimeControl.Base < 0 || inv.TimeControl.Increment < 0 {
+		return ErrInvalidInvite
+	}
+	if inv.Rated && (len(inv.FEN) > 0 || inv.Handicap != nil) {
+		return ErrInvalidInvite
+	}
+
+	switch inv.Color {
+	case "":
+		inv.Color = model.ColorRandom
+	case model.ColorWhite, model.ColorBlack, model.ColorRandom:
+	default:
+		return ErrInvalidInvite
+	}
+
+	if len(inv.FEN) > 0 {
+		_, err := board.ParseFEN(inv.FEN)
+		if err != nil {
+			return err
+		}
+	}
+
+	err := game.ValidHandicap(inv.Handicap)
+	if err != nil {
+		return err
 	}
+
 	id := u.Profile.GetInviteID()
 
 	var vs *User
 	for _, v := range users {
-		pro := v.Profile
-		if v.Client().Game() == nil {
-			if pro == inv.Profile {
-				vs = v
-				break
-			}
+		if v.Profile == inv.Profile {
+			vs = v
+			break
 		}
 	}
 
@@ -39,9 +104,26 @@ func (u *User) Invite(inv model.InviteOrder, lifespan time.Duration) error {
 	if vs == u {
 		return game.ErrClientNil
 	}
+	if blocked(u.Profile, vs.Profile) {
+		return ErrBlocked
+	}
+
+	u.mtx.Lock()
+	if time.Since(u.lastInvite) < InviteInterval {
+		u.mtx.Unlock()
+		return ErrInviteRate
+	}
+	u.lastInvite = time.Now()
+	u.mtx.Unlock()
 
 	param := model.InviteOrder{
-		Profile: u.Profile,
+		Profile:     u.Profile,
+		Color:       inv.Color,
+		FEN:         inv.FEN,
+		Handicap:    inv.Handicap,
+		Days:        inv.Days,
+		TimeControl: inv.TimeControl,
+		Rated:       inv.Rated,
 	}
 
 	body, err := json.Marshal(param)
@@ -49,10 +131,7 @@ func (u *User) Invite(inv model.InviteOrder, lifespan time.Duration) error {
 		return err
 	}
 
-	// u invited vs
-	vs.mtx.Lock()
-	vs.invite[id] = u
-	vs.mtx.Unlock()
+	pi := &pendingInvite{vs: u, inv: param, expires: time.Now().Add(lifespan)}
 
 	gu := model.Order{
 		ID:   model.OrInvite,
@@ -63,103 +142,286 @@ func (u *User) Invite(inv model.InviteOrder, lifespan time.Duration) error {
 		return err
 	}
 
-	// delete after X amount of time
-	go func(vs *User, id string) {
-		<-time.After(lifespan)
-		vs.mtx.Lock()
-		delete(vs.invite, id)
+	// u invited vs
+	vs.mtx.Lock()
+	if _, ok := vs.invite[id]; ok {
 		vs.mtx.Unlock()
-	}(vs, id)
+		return ErrInviteRate
+	}
+	vs.invite[id] = pi
+	// delete after X amount of time, unless it was accepted, declined or cancelled
+	pi.timer = time.AfterFunc(lifespan, func() {
+		if vs.takeInvite(id, pi) == nil {
+			return
+		}
+
+		notifyInvite(vs, model.OrInviteExpired, u.Profile)
+		notifyInvite(u, model.OrInviteExpired, vs.Profile)
+	})
+	vs.mtx.Unlock()
 
 	vs.Client().W.Write(send)
 
 	return nil
 }
 
-// AcceptInvite accepts the invite from the user.
+// AcceptInvite accepts the invite from the user. The colors and starting position are the ones chosen by the inviter.
 func (u *User) AcceptInvite(tok string) error {
-	u.mtx.Lock()
-	vs, ok := u.invite[tok]
-	u.mtx.Unlock()
-	if !ok {
+	// the invite is taken first, so that accepting it twice doesn't start two games
+	pi := u.takeInvite(tok, nil)
+	if pi == nil {
 		return ErrInvalidInvite
 	}
 
+	vs := pi.vs
 	if vs == nil || vs.Client() == nil {
 		return game.ErrClientNil
 	}
+	// either player could've blocked the other after the invite was sent
+	if blocked(u.Profile, vs.Profile) {
+		return ErrBlocked
+	}
 
-	g, err := game.NewGame(u.Client(), vs.Client())
+	opts, p1 := pi.options()
+
+	var err error
+	if p1 {
+		_, err = startGame(vs, u, opts)
+	} else {
+		_, err = startGame(u, vs, opts)
+	}
 	if err != nil {
+		// the invite could be accepted again, it expires as usual
+		u.mtx.Lock()
+		if _, ok := u.invite[tok]; !ok && u.invite != nil {
+			u.invite[tok] = pi
+			pi.timer.Reset(time.Until(pi.expires))
+		}
+		u.mtx.Unlock()
+
 		return err
 	}
 
+	u.dropInvites()
+	vs.dropInvites()
+
+	return nil
+}
+
+// takeInvite removes the invite with id from the user's invites, and returns it. If pi isn't nil, the invite is only removed if it's still pi.
+func (u *User) takeInvite(id string, pi *pendingInvite) *pendingInvite {
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+
+	v, ok := u.invite[id]
+	if !ok || (pi != nil && v != pi) {
+		return nil
+	}
+
+	delete(u.invite, id)
+	if v.timer != nil {
+		v.timer.Stop()
+	}
+	return v
+}
+
+// dropInvites removes every invite the user received, as they started a game. The inviters get notified via model.OrInviteDeclined.
+func (u *User) dropInvites() {
+	u.mtx.Lock()
+	invs := u.invite
+	u.invite = map[string]*pendingInvite{}
+	u.mtx.Unlock()
+
+	for _, pi := range invs {
+		if pi.timer != nil {
+			pi.timer.Stop()
+		}
+
+		notifyInvite(pi.vs, model.OrInviteDeclined, u.Profile)
+	}
+}
+
+// notifyInvite sends the update with id to the user, pro is the other party of the invite.
+func notifyInvite(u *User, id uint8, pro model.Profile) {
+	cl := u.Client()
+	if cl == nil {
+		return
+	}
+
+	body, err := json.Marshal(model.InviteOrder{
+		Profile: pro,
+	})
+	if err != nil {
+		return
+	}
+
+	send, err := json.Marshal(model.Order{
+		ID:   id,
+		Data: body,
+	})
+	if err != nil {
+		return
+	}
+
+	cl.W.Write(send)
+}
+
+// DeclineInvite declines the invite from the user with tok, the inviter gets notified via model.OrInviteDeclined.
+func (u *User) DeclineInvite(tok string) error {
+	pi := u.takeInvite(tok, nil)
+	if pi == nil {
+		return ErrInvalidInvite
+	}
+
+	notifyInvite(pi.vs, model.OrInviteDeclined, u.Profile)
+	return nil
+}
+
+// CancelInvite withdraws the user's invite to pro, the invitee gets notified via model.OrInviteCancelled.
+func (u *User) CancelInvite(pro model.Profile) error {
+	vs := userByProfile(pro)
+	if vs == nil {
+		return ErrInvalidInvite
+	}
+
+	pi := vs.takeInvite(u.Profile.GetInviteID(), nil)
+	if pi == nil {
+		return ErrInvalidInvite
+	}
+
+	notifyInvite(vs, model.OrInviteCancelled, u.Profile)
+	return nil
+}
+
+// Invites returns the user's pending invites, both the ones they received and the ones they sent.
+func (u *User) Invites() model.Invites {
+	invs := model.Invites{
+		Incoming: []model.PendingInvite{},
+		Outgoing: []model.PendingInvite{},
+	}
+
+	u.mtx.Lock()
+	for _, pi := range u.invite {
+		invs.Incoming = append(invs.Incoming, model.PendingInvite{InviteOrder: pi.inv, Expires: pi.expires})
+	}
+	u.mtx.Unlock()
+
+	usermtx.Lock()
+	sl := make([]*User, 0, len(users))
+	for _, v := range users {
+		if v != u {
+			sl = append(sl, v)
+		}
+	}
+	usermtx.Unlock()
+
+	id := u.Profile.GetInviteID()
+	for _, v := range sl {
+		v.mtx.Lock()
+		pi, ok := v.invite[id]
+		v.mtx.Unlock()
+
+		if ok && pi.vs == u {
+			inv := pi.inv
+			inv.Profile = v.Profile
+			invs.Outgoing = append(invs.Outgoing, model.PendingInvite{InviteOrder: inv, Expires: pi.expires})
+		}
+	}
+
+	return invs
+}
+
+// startGame creates a game between u1 and u2, where u1 is player one. It sends the game to both users, and makes it watchable.
+// The users' main clients are used whenever they're not in a game. Correspondence games always get their own clients, so that they don't take the main client.
+func startGame(u1, u2 *User, opts model.GameOptions) (*game.Game, error) {
+	c1, c2 := u1.freeClient(), u2.freeClient()
+	if opts.Days > 0 {
+		c1, c2 = u1.newClient(), u2.newClient()
+	}
+
+	g, err := game.NewGameWithOptions(c1, c2, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	u1.addGame(g.ID(), c1)
+	u2.addGame(g.ID(), c2)
+
 	b := g.Board()
 
 	cancel := func(err error) error {
-		go u.Client().LeaveGame()
-		go vs.Client().LeaveGame()
+		go c1.LeaveGame()
+		go c2.LeaveGame()
 
 		return fmt.Errorf("%s | %w", err.Error(), ErrInternal)
 	}
 
-	p1 := u.Client().P1()
+	p1 := c1.P1()
 	jsu, err := json.Marshal(model.GameOrder{
+		ID:      g.ID(),
 		P1:      &p1,
-		Profile: &vs.Profile,
+		Profile: &u2.Profile,
 		Brd:     b,
 	})
 	if err != nil {
-		return cancel(err)
+		return nil, cancel(err)
 	}
-	p1 = vs.Client().P1()
+	p1 = c2.P1()
 	jsv, err := json.Marshal(model.GameOrder{
+		ID:      g.ID(),
 		P1:      &p1,
-		Profile: &u.Profile,
+		Profile: &u1.Profile,
 		Brd:     b,
 	})
 	if err != nil {
-		return cancel(err)
+		return nil, cancel(err)
 	}
 	data, err := json.Marshal(model.Order{
 		ID:   model.OrGame,
 		Data: jsu,
 	})
 	if err != nil {
-		return cancel(err)
+		return nil, cancel(err)
 	}
-	u.Client().W.Write(data)
+	c1.W.Write(data)
 	data, err = json.Marshal(model.Order{
 		ID:   model.OrGame,
 		Data: jsv,
 	})
 	if err != nil {
-		return cancel(err)
+		return nil, cancel(err)
 	}
-	vs.Client().W.Write(data)
+	c2.W.Write(data)
 
 	g.SwitchTurn()
+	watchGame(u1, u2, g)
+
+	return g, nil
+}
+
+// watchGame makes the game watchable until it ends, then sets the rematch of both users. The users' seeks are cancelled, unless it's a correspondence game.
+func watchGame(u1, u2 *User, g *game.Game) {
+	if !g.Correspondence() {
+		u1.CancelSeek()
+		u2.CancelSeek()
+	}
 
 	id := watchable.Add(watchableModel{
-		p1: u.Profile,
-		p2: u.Profile,
+		p1: u1.Profile,
+		p2: u2.Profile,
 		gm: g,
 	})
 
+	pro1, pro2 := u1.Profile, u2.Profile
 	go func() {
 		<-g.ListenForDone()
 		watchable.Rm(id)
-	}()
+		finish(g, pro1, pro2)
 
-	u.mtx.Lock()
-	u.invite = map[string]*User{}
-	u.mtx.Unlock()
-
-	vs.mtx.Lock()
-	u.invite = map[string]*User{}
-	vs.mtx.Unlock()
-
-	return nil
+		expires := time.Now().Add(RematchLifespan)
+		u1.setRematch(&rematch{vs: u2, opts: g.Options(), p1: true, expires: expires})
+		u2.setRematch(&rematch{vs: u1, opts: g.Options(), p1: false, expires: expires})
+	}()
 }
 
 func InviteHandler(w http.ResponseWriter, r *http.Request) {
@@ -208,3 +470,62 @@ func AcceptInviteHandler(w http.ResponseWriter, r *http.Request) {
 
 	RespondJSON(w, http.StatusOK, nil)
 }
+
+// DeclineInviteHandler declines the invite from the profile in the body.
+func DeclineInviteHandler(w http.ResponseWriter, r *http.Request) {
+	u, err := GetUser(r)
+	if err != nil {
+		RespondError(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	inv := model.InviteOrder{}
+	err = BindJSON(r, &inv)
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = u.DeclineInvite(inv.Profile.GetInviteID())
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, nil)
+}
+
+// CancelInviteHandler cancels the user's invite to the profile in the body.
+func CancelInviteHandler(w http.ResponseWriter, r *http.Request) {
+	u, err := GetUser(r)
+	if err != nil {
+		RespondError(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	inv := model.InviteOrder{}
+	err = BindJSON(r, &inv)
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = u.CancelInvite(inv.Profile)
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, nil)
+}
+
+// InvitesHandler returns the user's pending invites.
+func InvitesHandler(w http.ResponseWriter, r *http.Request) {
+	u, err := GetUser(r)
+	if err != nil {
+		RespondError(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, u.Invites())
+}
@@ -15,6 +15,13 @@ import (
 
 var _inviteCode = ""
 
+// resetInvite lets u invite again right away, for tests that aren't about InviteInterval.
+func resetInvite(u *User) {
+	u.mtx.Lock()
+	u.lastInvite = time.Time{}
+	u.mtx.Unlock()
+}
+
 func TestUserInvite(t *testing.T) {
 	go read(rd2)
 	us1.cl.LeaveGame()
@@ -39,7 +46,22 @@ func TestUserInvite(t *testing.T) {
 		t.Fatalf("vs invite map is empty")
 	}
 
-	<-time.After(lifespan * 2)
+	err = us1.Invite(model.InviteOrder{
+		Profile: us2.Profile,
+	}, lifespan)
+	if err != ErrInviteRate {
+		t.Fatalf("us.Invite: want: %v - have: %v", ErrInviteRate, err)
+	}
+
+	// both parties are notified once it expires
+	for _, rd := range []*io.PipeReader{rd2, rd1} {
+		o := model.Order{}
+		json.Unmarshal(<-read(rd), &o)
+		if o.ID != model.OrInviteExpired {
+			t.Fatalf("want: %d - have: %d", model.OrInviteExpired, o.ID)
+		}
+	}
+
 	if len(us2.invite) == 1 {
 		t.Fatalf("vs lifespan does not work")
 	}
@@ -54,10 +76,13 @@ func TestUserAcceptInvite(t *testing.T) {
 	}
 	// because net.Pipe is synchronous
 	ch := make(chan error)
+	resetInvite(us1)
 
 	go func() {
+		// the colors are fixed, as updates are read in order
 		err = us1.Invite(model.InviteOrder{
 			Profile: us2.Profile,
+			Color:   model.ColorBlack,
 		}, InviteLifespan)
 		if err != nil {
 			ch <- fmt.Errorf("us.Invite: %s", err.Error())
@@ -145,9 +170,12 @@ func TestUserAcceptInvite(t *testing.T) {
 func TestInviteHandler(t *testing.T) {
 	go read(rd2)
 	us1.cl.LeaveGame()
+	resetInvite(us1)
 
+	// the colors are fixed, as updates are read in order by TestAcceptInviteHandler
 	marshal, _ := json.Marshal(model.InviteOrder{
 		Profile: us2.Profile,
+		Color:   model.ColorBlack,
 	})
 
 	resp := httptest.NewRecorder()
@@ -214,3 +242,296 @@ func TestAcceptInviteHandler(t *testing.T) {
 	}
 
 }
+
+func TestUserInviteColor(t *testing.T) {
+	u1, _ := newDrainedUser(t)
+	u2, ch2 := newDrainedUser(t)
+	defer u1.Delete()
+	defer u2.Delete()
+
+	err := u1.Invite(model.InviteOrder{Profile: u2.Profile, Color: "purple"}, InviteLifespan)
+	if err != ErrInvalidInvite {
+		t.Fatalf("u.Invite: invalid color, want: %v - have: %v", ErrInvalidInvite, err)
+	}
+
+	fen := "4k3/8/8/8/8/8/8/4K3 w - - 0 1"
+	err = u1.Invite(model.InviteOrder{Profile: u2.Profile, Color: model.ColorWhite, FEN: fen}, InviteLifespan)
+	if err != nil {
+		t.Fatalf("u.Invite: %s", err.Error())
+	}
+
+	o := waitFor(t, ch2, model.OrInvite)
+	inv := model.InviteOrder{}
+	json.Unmarshal(o.Data, &inv)
+	if inv.Color != model.ColorWhite || inv.FEN != fen {
+		t.Fatalf("invite update does not have the color or the starting position: %+v", inv)
+	}
+
+	err = u2.AcceptInvite(u1.Profile.GetInviteID())
+	if err != nil {
+		t.Fatalf("u.AcceptInvite: %s", err.Error())
+	}
+
+	if !u1.Client().P1() || u2.Client().P1() {
+		t.Fatalf("inviter does not play white")
+	}
+	if u1.Client().Game().Options().FEN != fen {
+		t.Fatalf("game does not use the starting position")
+	}
+}
+
+func TestUserDeclineInvite(t *testing.T) {
+	u1, ch1 := newDrainedUser(t)
+	u2, ch2 := newDrainedUser(t)
+	defer u1.Delete()
+	defer u2.Delete()
+
+	err := u1.Invite(model.InviteOrder{Profile: u2.Profile, Days: 3}, InviteLifespan)
+	if err != nil {
+		t.Fatalf("u.Invite: %s", err.Error())
+	}
+	waitFor(t, ch2, model.OrInvite)
+
+	invs := u1.Invites()
+	if len(invs.Outgoing) != 1 || len(invs.Incoming) != 0 || invs.Outgoing[0].Profile != u2.Profile || invs.Outgoing[0].Days != 3 {
+		t.Fatalf("unexpected invites: %+v", invs)
+	}
+	invs = u2.Invites()
+	if len(invs.Incoming) != 1 || invs.Incoming[0].Profile != u1.Profile {
+		t.Fatalf("unexpected invites: %+v", invs)
+	}
+
+	err = u2.DeclineInvite(u1.Profile.GetInviteID())
+	if err != nil {
+		t.Fatalf("u.DeclineInvite: %s", err.Error())
+	}
+	waitFor(t, ch1, model.OrInviteDeclined)
+
+	err = u2.DeclineInvite(u1.Profile.GetInviteID())
+	if err != ErrInvalidInvite {
+		t.Fatalf("u.DeclineInvite: want: %v - have: %v", ErrInvalidInvite, err)
+	}
+
+	// the inviter could invite again once it's declined, and InviteInterval has passed
+	resetInvite(u1)
+	err = u1.Invite(model.InviteOrder{Profile: u2.Profile}, InviteLifespan)
+	if err != nil {
+		t.Fatalf("u.Invite: %s", err.Error())
+	}
+	waitFor(t, ch2, model.OrInvite)
+
+	err = u1.CancelInvite(u2.Profile)
+	if err != nil {
+		t.Fatalf("u.CancelInvite: %s", err.Error())
+	}
+	o := waitFor(t, ch2, model.OrInviteCancelled)
+
+	inv := model.InviteOrder{}
+	json.Unmarshal(o.Data, &inv)
+	if inv.Profile != u1.Profile {
+		t.Fatalf("unexpected profile: %+v", inv.Profile)
+	}
+
+	if len(u1.Invites().Outgoing) != 0 || len(u2.Invites().Incoming) != 0 {
+		t.Fatalf("cancelled invite is still pending")
+	}
+}
+
+func TestUserInviteInterval(t *testing.T) {
+	old := InviteInterval
+	InviteInterval = time.Millisecond * 50
+	defer func() { InviteInterval = old }()
+
+	u1, _ := newDrainedUser(t)
+	u2, ch2 := newDrainedUser(t)
+	defer u1.Delete()
+	defer u2.Delete()
+
+	err := u1.Invite(model.InviteOrder{Profile: u2.Profile}, InviteLifespan)
+	if err != nil {
+		t.Fatalf("u.Invite: %s", err.Error())
+	}
+	waitFor(t, ch2, model.OrInvite)
+
+	err = u1.CancelInvite(u2.Profile)
+	if err != nil {
+		t.Fatalf("u.CancelInvite: %s", err.Error())
+	}
+
+	// cancelling doesn't let the inviter spam invites
+	err = u1.Invite(model.InviteOrder{Profile: u2.Profile}, InviteLifespan)
+	if err != ErrInviteRate {
+		t.Fatalf("u.Invite: want: %v - have: %v", ErrInviteRate, err)
+	}
+
+	time.Sleep(InviteInterval)
+
+	err = u1.Invite(model.InviteOrder{Profile: u2.Profile}, InviteLifespan)
+	if err != nil {
+		t.Fatalf("u.Invite: %s", err.Error())
+	}
+	waitFor(t, ch2, model.OrInvite)
+}
+
+func TestUserAcceptInviteDropsOthers(t *testing.T) {
+	u1, ch1 := newDrainedUser(t)
+	u2, ch2 := newDrainedUser(t)
+	u3, ch3 := newDrainedUser(t)
+	defer u1.Delete()
+	defer u2.Delete()
+	defer u3.Delete()
+
+	const lifespan = time.Millisecond * 30
+	err := u3.Invite(model.InviteOrder{Profile: u2.Profile}, lifespan)
+	if err != nil {
+		t.Fatalf("u.Invite: %s", err.Error())
+	}
+	err = u1.Invite(model.InviteOrder{Profile: u2.Profile}, InviteLifespan)
+	if err != nil {
+		t.Fatalf("u.Invite: %s", err.Error())
+	}
+
+	err = u2.AcceptInvite(u1.Profile.GetInviteID())
+	if err != nil {
+		t.Fatalf("u.AcceptInvite: %s", err.Error())
+	}
+	waitFor(t, ch1, model.OrGame)
+	waitFor(t, ch2, model.OrGame)
+
+	o := waitFor(t, ch3, model.OrInviteDeclined)
+	inv := model.InviteOrder{}
+	json.Unmarshal(o.Data, &inv)
+	if inv.Profile != u2.Profile {
+		t.Fatalf("unexpected profile: %+v", inv.Profile)
+	}
+
+	// the dropped invite doesn't expire later on
+	timeout := time.After(lifespan * 2)
+	for {
+		select {
+		case o := <-ch3:
+			if o.ID == model.OrInviteExpired {
+				t.Fatalf("dropped invite expired")
+			}
+		case <-timeout:
+			return
+		}
+	}
+}
+
+func TestUserAcceptInviteTwice(t *testing.T) {
+	u1, _ := newDrainedUser(t)
+	u2, _ := newDrainedUser(t)
+	defer u1.Delete()
+	defer u2.Delete()
+
+	err := u1.Invite(model.InviteOrder{Profile: u2.Profile}, InviteLifespan)
+	if err != nil {
+		t.Fatalf("u.Invite: %s", err.Error())
+	}
+
+	ch := make(chan error)
+	for i := 0; i < 2; i++ {
+		go func() {
+			ch <- u2.AcceptInvite(u1.Profile.GetInviteID())
+		}()
+	}
+
+	errs := []error{<-ch, <-ch}
+	if (errs[0] == nil) == (errs[1] == nil) {
+		t.Fatalf("u.AcceptInvite: want one game - have: %v", errs)
+	}
+
+	if len(u2.Games()) != 1 {
+		t.Fatalf("accepting the invite twice starts %d games", len(u2.Games()))
+	}
+}
+
+func TestUserAcceptInviteFailed(t *testing.T) {
+	u1, _ := newDrainedUser(t)
+	u2, _ := newDrainedUser(t)
+	defer u1.Delete()
+	defer u2.Delete()
+
+	err := u1.Invite(model.InviteOrder{Profile: u2.Profile}, InviteLifespan)
+	if err != nil {
+		t.Fatalf("u.Invite: %s", err.Error())
+	}
+
+	// the game can't be created without a writer
+	cl := u2.Client()
+	w := cl.W
+	cl.W = nil
+	err = u2.AcceptInvite(u1.Profile.GetInviteID())
+	cl.W = w
+	if err == nil {
+		t.Fatalf("u.AcceptInvite: game is started without a writer")
+	}
+
+	if len(u2.Invites().Incoming) != 1 {
+		t.Fatalf("invite is gone after failing to start the game")
+	}
+
+	err = u2.AcceptInvite(u1.Profile.GetInviteID())
+	if err != nil {
+		t.Fatalf("u.AcceptInvite: %s", err.Error())
+	}
+}
+
+func TestUserInviteColorRandom(t *testing.T) {
+	u1, _ := newDrainedUser(t)
+	u2, ch2 := newDrainedUser(t)
+	defer u1.Delete()
+	defer u2.Delete()
+
+	err := u1.Invite(model.InviteOrder{Profile: u2.Profile}, InviteLifespan)
+	if err != nil {
+		t.Fatalf("u.Invite: %s", err.Error())
+	}
+
+	o := waitFor(t, ch2, model.OrInvite)
+	inv := model.InviteOrder{}
+	json.Unmarshal(o.Data, &inv)
+	if inv.Color != model.ColorRandom {
+		t.Fatalf("empty color: want: %s - have: %q", model.ColorRandom, inv.Color)
+	}
+}
+
+func TestUserInviteTimeControl(t *testing.T) {
+	u1, _ := newDrainedUser(t)
+	u2, ch2 := newDrainedUser(t)
+	defer u1.Delete()
+	defer u2.Delete()
+
+	blitz := model.TimeControl{Base: 300, Increment: 2}
+	err := u1.Invite(model.InviteOrder{Profile: u2.Profile, TimeControl: model.TimeControl{Base: -1}}, InviteLifespan)
+	if err != ErrInvalidInvite {
+		t.Fatalf("u.Invite: negative time control, want: %v - have: %v", ErrInvalidInvite, err)
+	}
+	err = u1.Invite(model.InviteOrder{Profile: u2.Profile, Rated: true, FEN: "4k3/8/8/8/8/8/8/4K3 w - - 0 1"}, InviteLifespan)
+	if err != ErrInvalidInvite {
+		t.Fatalf("u.Invite: rated custom position, want: %v - have: %v", ErrInvalidInvite, err)
+	}
+
+	err = u1.Invite(model.InviteOrder{Profile: u2.Profile, TimeControl: blitz, Rated: true}, InviteLifespan)
+	if err != nil {
+		t.Fatalf("u.Invite: %s", err.Error())
+	}
+
+	o := waitFor(t, ch2, model.OrInvite)
+	inv := model.InviteOrder{}
+	json.Unmarshal(o.Data, &inv)
+	if inv.TimeControl != blitz || !inv.Rated {
+		t.Fatalf("invite does not show the time control: %+v", inv)
+	}
+
+	err = u2.AcceptInvite(u1.Profile.GetInviteID())
+	if err != nil {
+		t.Fatalf("u.AcceptInvite: %s", err.Error())
+	}
+
+	opts := u1.Client().Game().Options()
+	if opts.TimeControl != blitz || !opts.Rated || u1.Client().Game().Clock() == nil {
+		t.Fatalf("game does not use the invite's time control: %+v", opts)
+	}
+}
@@ -0,0 +1,116 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/toms1441/chess-server/internal/model"
+	"github.com/toms1441/chess-server/internal/puzzle"
+)
+
+// PuzzleHandler starts a new puzzle for the user. The puzzle is random unless the id query parameter is set.
+func PuzzleHandler(w http.ResponseWriter, r *http.Request) {
+	u, err := GetUser(r)
+	if err != nil {
+		RespondError(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	var pz puzzle.Puzzle
+	if id := r.URL.Query().Get("id"); len(id) > 0 {
+		pz, err = puzzle.Get(id)
+	} else {
+		pz, err = puzzle.Random()
+	}
+	if err != nil {
+		RespondError(w, http.StatusNotFound, err)
+		return
+	}
+
+	s, err := puzzle.NewSession(pz)
+	if err != nil {
+		RespondError(w, http.StatusInternalServerError, fmt.Errorf("%s | %w", err.Error(), ErrInternal))
+		return
+	}
+
+	u.mtx.Lock()
+	u.puzzle = s
+	u.mtx.Unlock()
+
+	RespondJSON(w, http.StatusOK, model.Puzzle{
+		ID:     pz.ID,
+		Rating: pz.Rating,
+		Themes: pz.Themes,
+		P1:     s.P1(),
+		Brd:    s.Board(),
+	})
+}
+
+// PuzzleMoveHandler validates the user's move against the puzzle's solution. The opponent's reply is sent via the websocket connection.
+func PuzzleMoveHandler(w http.ResponseWriter, r *http.Request) {
+	u, err := GetUser(r)
+	if err != nil {
+		RespondError(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	u.mtx.Lock()
+	s := u.puzzle
+	u.mtx.Unlock()
+	if s == nil {
+		RespondError(w, http.StatusNotFound, puzzle.ErrPuzzleNil)
+		return
+	}
+
+	// BindJSON is not used, cause board.Point's validation does not allow zero values
+	mo := model.MoveOrder{}
+	err = json.NewDecoder(r.Body).Decode(&mo)
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, fmt.Errorf("json: %w", err))
+		return
+	}
+
+	orders, err := s.Move(mo)
+	if err != nil && !errors.Is(err, puzzle.ErrWrongMove) {
+		RespondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if s.Done() {
+		puzzle.Record(u.Profile, s.Puzzle().ID, s.Solved())
+
+		u.mtx.Lock()
+		if u.puzzle == s {
+			u.puzzle = nil
+		}
+		u.mtx.Unlock()
+	}
+
+	RespondJSON(w, http.StatusOK, model.PuzzleState{
+		Done:   s.Done(),
+		Solved: s.Solved(),
+	})
+
+	for _, v := range orders {
+		v.Game = s.Puzzle().ID
+		body, err := json.Marshal(v)
+		if err != nil {
+			continue
+		}
+
+		u.conns.Write(body)
+	}
+}
+
+// PuzzleRecordHandler returns the user's solved and failed puzzles.
+func PuzzleRecordHandler(w http.ResponseWriter, r *http.Request) {
+	u, err := GetUser(r)
+	if err != nil {
+		RespondError(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, puzzle.GetRecord(u.Profile))
+}
@@ -0,0 +1,131 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/toms1441/chess-server/internal/board"
+	"github.com/toms1441/chess-server/internal/model"
+	"github.com/toms1441/chess-server/internal/model/local"
+	"github.com/toms1441/chess-server/internal/puzzle"
+)
+
+var pzUs = &User{}
+
+func TestPuzzleHandler(t *testing.T) {
+	var err error
+	_, pzW := io.Pipe()
+	pzUs, err = AddClient(local.NewUser(), pzW)
+	if err != nil {
+		t.Fatalf("AddClient: %s", err.Error())
+	}
+
+	puzzle.Set([]puzzle.Puzzle{
+		{
+			ID:     "mate",
+			FEN:    "6k1/5ppp/8/8/8/8/5PPP/R5K1 b - - 0 1",
+			Moves:  []string{"g8h8", "a1a8"},
+			Rating: 600,
+		},
+	})
+
+	resp := httptest.NewRecorder()
+	req, err := http.NewRequest("GET", "/?id=mate", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", pzUs.Token))
+
+	http.HandlerFunc(PuzzleHandler).ServeHTTP(resp, req)
+	if resp.Result().StatusCode != http.StatusOK {
+		t.Log(resp.Body.String())
+		t.Fatalf("http.StatusCode: %d", resp.Result().StatusCode)
+	}
+
+	pz := model.Puzzle{}
+	err = json.Unmarshal(resp.Body.Bytes(), &pz)
+	if err != nil {
+		t.Fatalf("json.Unmarshal: %s", err.Error())
+	}
+
+	if pz.ID != "mate" || !pz.P1 || pz.Brd == nil {
+		t.Fatalf("invalid puzzle: %v", pz)
+	}
+}
+
+func TestPuzzleMoveHandler(t *testing.T) {
+	rook := board.GetRooks(true)[0]
+	body, _ := json.Marshal(model.MoveOrder{
+		ID:  rook,
+		Dst: board.Point{X: 0, Y: 0},
+	})
+
+	resp := httptest.NewRecorder()
+	req, err := http.NewRequest("POST", "/", bytes.NewBuffer(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", pzUs.Token))
+
+	http.HandlerFunc(PuzzleMoveHandler).ServeHTTP(resp, req)
+	if resp.Result().StatusCode != http.StatusOK {
+		t.Log(resp.Body.String())
+		t.Fatalf("http.StatusCode: %d", resp.Result().StatusCode)
+	}
+
+	state := model.PuzzleState{}
+	err = json.Unmarshal(resp.Body.Bytes(), &state)
+	if err != nil {
+		t.Fatalf("json.Unmarshal: %s", err.Error())
+	}
+
+	if !state.Done || !state.Solved {
+		t.Fatalf("puzzle should be solved: %v", state)
+	}
+
+	rec := puzzle.GetRecord(pzUs.Profile)
+	if len(rec.Solved) != 1 {
+		t.Fatalf("solved puzzle is not recorded: %v", rec)
+	}
+
+	pzUs.Delete()
+}
+
+func TestPuzzleMoveHandlerReply(t *testing.T) {
+	u, ch := newDrainedUser(t)
+	defer u.Delete()
+
+	s, err := puzzle.NewSession(puzzle.Puzzle{
+		ID:    "reply",
+		FEN:   "6k1/5ppp/8/8/8/8/5PPP/R5K1 b - - 0 1",
+		Moves: []string{"g8h8", "a1a2", "h7h6", "a2a8"},
+	})
+	if err != nil {
+		t.Fatalf("puzzle.NewSession: %s", err.Error())
+	}
+	u.puzzle = s
+
+	body, _ := json.Marshal(model.MoveOrder{
+		ID:  board.GetRooks(true)[0],
+		Dst: board.Point{X: 0, Y: 6},
+	})
+
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", u.Token))
+
+	http.HandlerFunc(PuzzleMoveHandler).ServeHTTP(resp, req)
+	if resp.Code != http.StatusOK {
+		t.Fatalf("http.StatusCode: %d - %s", resp.Code, resp.Body.String())
+	}
+
+	o := waitFor(t, ch, model.OrMove)
+	if o.Game != "reply" {
+		t.Fatalf("reply does not have the puzzle's id: %q", o.Game)
+	}
+}
@@ -0,0 +1,57 @@
+package rest
+
+import (
+	"encoding/json"
+
+	"github.com/toms1441/chess-server/internal/game"
+	"github.com/toms1441/chess-server/internal/model"
+	"github.com/toms1441/chess-server/internal/rating"
+)
+
+// rating.go updates the ratings of both players whenever a rated game ends.
+
+// Rating returns the user's rating in the category.
+func (u *User) Rating(cat string) int {
+	return rating.Ratings(u.Profile)[cat]
+}
+
+// rate updates the ratings of both players whenever the game is rated and has a result, pro1 and pro2 are the profiles of the players. Both players are sent another model.OrDone with the rating changes.
+func rate(g *game.Game, pro1, pro2 model.Profile) *model.RatingChange {
+	opts := g.Options()
+	if !opts.Rated {
+		return nil
+	}
+
+	reason := g.Reason()
+	score, ok := rating.Score(reason)
+	if !ok {
+		return nil
+	}
+
+	ch := rating.Record(pro1, pro2, rating.Category(opts), score)
+
+	body, err := json.Marshal(model.DoneOrder{
+		Reason:  reason,
+		Ratings: &ch,
+	})
+	if err != nil {
+		return &ch
+	}
+
+	body, err = json.Marshal(model.Order{
+		ID:   model.OrDone,
+		Data: body,
+		Game: g.ID(),
+	})
+	if err != nil {
+		return &ch
+	}
+
+	for _, pro := range []model.Profile{pro1, pro2} {
+		if u := userByProfile(pro); u != nil {
+			u.conns.Write(body)
+		}
+	}
+
+	return &ch
+}
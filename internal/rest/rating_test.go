@@ -0,0 +1,66 @@
+package rest
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/toms1441/chess-server/internal/model"
+	"github.com/toms1441/chess-server/internal/rating"
+)
+
+// waitForRatings waits for the model.OrDone update that has the rating changes.
+func waitForRatings(t *testing.T, ch chan model.Order) model.RatingChange {
+	for {
+		o := waitFor(t, ch, model.OrDone)
+
+		done := model.DoneOrder{}
+		json.Unmarshal(o.Data, &done)
+		if done.Ratings != nil {
+			return *done.Ratings
+		}
+	}
+}
+
+func TestUserRating(t *testing.T) {
+	rating.Reset()
+	defer rating.Reset()
+
+	u1, ch1 := newDrainedUser(t)
+	u2, ch2 := newDrainedUser(t)
+	defer u1.Delete()
+	defer u2.Delete()
+
+	blitz := model.TimeControl{Base: 300}
+	err := u1.Seek(model.Seek{TimeControl: blitz, Rated: true, Color: model.ColorWhite})
+	if err != nil {
+		t.Fatalf("u.Seek: %s", err.Error())
+	}
+	err = u2.Seek(model.Seek{TimeControl: blitz, Rated: true})
+	if err != nil {
+		t.Fatalf("u.Seek: %s", err.Error())
+	}
+
+	waitFor(t, ch1, model.OrGame)
+	waitFor(t, ch2, model.OrGame)
+
+	// black resigns
+	err = u2.Client().Do(model.Order{ID: model.OrDone})
+	if err != nil {
+		t.Fatalf("cl.Do: %s", err.Error())
+	}
+
+	ch := waitForRatings(t, ch1)
+	if ch.Category != rating.Blitz || ch.D1 <= 0 || ch.D2 >= 0 {
+		t.Fatalf("unexpected rating change: %+v", ch)
+	}
+	if waitForRatings(t, ch2) != ch {
+		t.Fatalf("players got different rating changes")
+	}
+
+	if u1.Rating(rating.Blitz) != ch.P1 || u2.Rating(rating.Blitz) != ch.P2 {
+		t.Fatalf("ratings weren't updated: %d %d", u1.Rating(rating.Blitz), u2.Rating(rating.Blitz))
+	}
+	if u1.Rating(rating.Bullet) != rating.DefaultRating {
+		t.Fatalf("other categories shouldn't change: %d", u1.Rating(rating.Bullet))
+	}
+}
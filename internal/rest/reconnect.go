@@ -0,0 +1,121 @@
+package rest
+
+import (
+	"io"
+	"time"
+
+	"github.com/toms1441/chess-server/internal/game"
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+// ReconnectLifespan is how long a player that lost their connection has to reconnect, before they forfeit the game.
+var ReconnectLifespan = time.Second * 60
+
+// disconnect is called whenever the user's connection w closes. Once every connection is closed, players of live games keep their games for ReconnectLifespan, everyone else gets deleted.
+func (u *User) disconnect(w io.WriteCloser) {
+	cl := u.Client()
+	if cl == nil {
+		// the user is deleted
+		return
+	}
+
+	ok, left := u.conns.remove(w)
+	if !ok || left > 0 {
+		// the user is already disconnected, or has other connections
+		return
+	}
+
+	u.CancelSeek()
+
+	u.detach()
+
+	if !u.playing() {
+		u.Delete()
+		return
+	}
+
+	cl.Disconnect()
+	for _, v := range u.clients() {
+		v.Disconnect()
+	}
+	u.waitReconnect()
+}
+
+// waitReconnect deletes the user if they don't reconnect within ReconnectLifespan.
+func (u *User) waitReconnect() {
+	u.mtx.Lock()
+	defer u.mtx.Unlock()
+
+	if u.reconnectTimer != nil {
+		u.reconnectTimer.Stop()
+	}
+	u.reconnectTimer = time.AfterFunc(ReconnectLifespan, func() {
+		cl := u.Client()
+		if cl != nil && !cl.Connected() {
+			u.Delete()
+		}
+	})
+}
+
+// reconnect adds the connection w to the user, and sends the state of their games.
+func (u *User) reconnect(w io.WriteCloser) error {
+	cl := u.Client()
+	if cl == nil || cl.Connected() || w == nil {
+		return ErrInvalidReconnect
+	}
+	u.conns.add(w)
+
+	u.mtx.Lock()
+	if u.reconnectTimer != nil {
+		u.reconnectTimer.Stop()
+		u.reconnectTimer = nil
+	}
+	u.mtx.Unlock()
+
+	var pro *model.Profile
+	if vs := u.opponent(); vs != nil {
+		pro = &vs.Profile
+	}
+
+	err := cl.Reconnect(u.conns, pro)
+	// the user could only be playing their other games
+	if err != nil && err != game.ErrGameNil {
+		return err
+	}
+
+	for _, v := range u.clients() {
+		if !v.Connected() {
+			v.Reconnect(u.conns, opponentOf(v.Game(), v))
+		}
+	}
+
+	return nil
+}
+
+// opponent returns the user playing against u, or nil if there's none.
+func (u *User) opponent() *User {
+	cl := u.Client()
+	if cl == nil {
+		return nil
+	}
+
+	g := cl.Game()
+	if g == nil {
+		return nil
+	}
+
+	usermtx.Lock()
+	defer usermtx.Unlock()
+	for _, v := range users {
+		vc := v.Client()
+		if v == u || vc == nil {
+			continue
+		}
+
+		if vc.Game() == g && g.IsPlayer(vc) {
+			return v
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,104 @@
+package rest
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+func TestUserReconnect(t *testing.T) {
+	u1, ch1 := newDrainedUser(t)
+	u2, _ := newDrainedUser(t)
+	defer u1.Delete()
+	defer u2.Delete()
+
+	_, err := startGame(u1, u2, model.GameOptions{})
+	if err != nil {
+		t.Fatalf("startGame: %s", err.Error())
+	}
+
+	err = u2.reconnect(nil)
+	if err != ErrInvalidReconnect {
+		t.Fatalf("u.reconnect: want: %v - have: %v", ErrInvalidReconnect, err)
+	}
+
+	u2.disconnect(u2.Client().W)
+	waitFor(t, ch1, model.OrDisconnected)
+
+	if _, ok := users[u2.Token]; !ok {
+		t.Fatalf("user got deleted before ReconnectLifespan")
+	}
+
+	rd, wr := io.Pipe()
+	ch2 := drain(rd)
+
+	err = u2.reconnect(wr)
+	if err != nil {
+		t.Fatalf("u.reconnect: %s", err.Error())
+	}
+
+	o := waitFor(t, ch2, model.OrGame)
+	gme := model.GameOrder{}
+	json.Unmarshal(o.Data, &gme)
+	if gme.Profile == nil || *gme.Profile != u1.Profile {
+		t.Fatalf("game update does not have the opponent's profile")
+	}
+	waitFor(t, ch1, model.OrReconnected)
+}
+
+func TestUserReconnectLifespan(t *testing.T) {
+	old := ReconnectLifespan
+	ReconnectLifespan = time.Millisecond * 10
+	defer func() { ReconnectLifespan = old }()
+
+	u1, ch1 := newDrainedUser(t)
+	u2, _ := newDrainedUser(t)
+	defer u1.Delete()
+
+	_, err := startGame(u1, u2, model.GameOptions{})
+	if err != nil {
+		t.Fatalf("startGame: %s", err.Error())
+	}
+
+	u2.disconnect(u2.Client().W)
+	waitFor(t, ch1, model.OrDone)
+
+	// the opponent gets notified before the user is deleted
+	for i := 0; u2.Client() != nil; i++ {
+		if i == 10 {
+			t.Fatalf("user does not get deleted after ReconnectLifespan")
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+}
+
+func TestWebsocketReconnectProfile(t *testing.T) {
+	u1, _ := newDrainedUser(t)
+	u2, _ := newDrainedUser(t)
+	defer u1.Delete()
+	defer u2.Delete()
+
+	_, err := startGame(u1, u2, model.GameOptions{})
+	if err != nil {
+		t.Fatalf("startGame: %s", err.Error())
+	}
+
+	u2.disconnect(u2.Client().W)
+
+	// without any identify callback, the request is identified as a new local user
+	req := httptest.NewRequest("GET", "/?token="+u2.Token, nil)
+	resp := httptest.NewRecorder()
+	WebsocketHandler(resp, req)
+	if resp.Code != http.StatusUnauthorized {
+		t.Fatalf("another player reconnects with the token, status: %d", resp.Code)
+	}
+
+	if u2.Client().Connected() {
+		t.Fatalf("user is reconnected")
+	}
+}
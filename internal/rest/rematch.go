@@ -0,0 +1,122 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/toms1441/chess-server/internal/game"
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+const (
+	RematchLifespan = time.Second * 30
+)
+
+// rematch is the last game of the user, which could be played again with swapped colors.
+type rematch struct {
+	vs   *User
+	opts model.GameOptions
+	// p1 is true if the user was player one
+	p1 bool
+	// asked is true whenever the user asks for a rematch
+	asked   bool
+	expires time.Time
+}
+
+// rematchmtx is used so that two rematch requests don't start two games
+var rematchmtx sync.Mutex
+
+func (u *User) setRematch(re *rematch) {
+	u.mtx.Lock()
+	u.rematch = re
+	u.mtx.Unlock()
+}
+
+// Rematch asks for a rematch with the last opponent. If the opponent asked as well, a game starts with the colors swapped, and the same options.
+// Otherwise, the opponent gets notified via model.OrRematch.
+func (u *User) Rematch() error {
+	if !u.Valid() {
+		return game.ErrClientNil
+	}
+
+	rematchmtx.Lock()
+	defer rematchmtx.Unlock()
+
+	u.mtx.Lock()
+	re := u.rematch
+	if re == nil || time.Now().After(re.expires) {
+		u.rematch = nil
+		u.mtx.Unlock()
+		return ErrInvalidRematch
+	}
+	re.asked = true
+	u.mtx.Unlock()
+
+	vs := re.vs
+	if vs == nil || !vs.Valid() {
+		return game.ErrClientNil
+	}
+
+	vs.mtx.Lock()
+	ot := vs.rematch
+	both := ot != nil && ot.vs == u && ot.asked && time.Now().Before(ot.expires)
+	vs.mtx.Unlock()
+
+	if !both {
+		body, err := json.Marshal(model.InviteOrder{
+			Profile: u.Profile,
+		})
+		if err != nil {
+			return err
+		}
+
+		send, err := json.Marshal(model.Order{
+			ID:   model.OrRematch,
+			Data: body,
+		})
+		if err != nil {
+			return err
+		}
+
+		vs.conns.Write(send)
+		return nil
+	}
+
+	u.setRematch(nil)
+	vs.setRematch(nil)
+
+	// swap colors, the same player keeps giving the handicap
+	opts := re.opts
+	if opts.Handicap != nil {
+		h := *opts.Handicap
+		h.P1 = !h.P1
+		opts.Handicap = &h
+	}
+
+	var err error
+	if re.p1 {
+		_, err = startGame(vs, u, opts)
+	} else {
+		_, err = startGame(u, vs, opts)
+	}
+
+	return err
+}
+
+func RematchHandler(w http.ResponseWriter, r *http.Request) {
+	u, err := GetUser(r)
+	if err != nil {
+		RespondError(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	err = u.Rematch()
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, nil)
+}
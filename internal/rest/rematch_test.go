@@ -0,0 +1,234 @@
+package rest
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/toms1441/chess-server/internal/model"
+	"github.com/toms1441/chess-server/internal/model/local"
+)
+
+// drain reads every update written to rd, so that tests don't have to care about the order of updates.
+func drain(rd *io.PipeReader) chan model.Order {
+	ch := make(chan model.Order, 64)
+
+	go func() {
+		for {
+			body := make([]byte, 4096)
+			n, err := rd.Read(body)
+			if err != nil {
+				close(ch)
+				return
+			}
+
+			o := model.Order{}
+			json.Unmarshal(body[:n], &o)
+			ch <- o
+		}
+	}()
+
+	return ch
+}
+
+// waitFor waits for an update with id, discarding any other update.
+func waitFor(t *testing.T, ch chan model.Order, id uint8) model.Order {
+	for {
+		select {
+		case <-time.After(time.Millisecond * 100):
+			t.Fatalf("timeout while waiting for update %d", id)
+		case o := <-ch:
+			if o.ID == id {
+				return o
+			}
+		}
+	}
+}
+
+// newDrainedUser adds a user that has their updates drained.
+func newDrainedUser(t *testing.T) (*User, chan model.Order) {
+	rd, wr := io.Pipe()
+
+	u, err := AddClient(local.NewUser(), wr)
+	if err != nil {
+		t.Fatalf("AddClient: %s", err.Error())
+	}
+
+	return u, drain(rd)
+}
+
+func TestUserRematch(t *testing.T) {
+	u1, ch1 := newDrainedUser(t)
+	u2, ch2 := newDrainedUser(t)
+	defer u1.Delete()
+	defer u2.Delete()
+
+	err := u1.Rematch()
+	if err != ErrInvalidRematch {
+		t.Fatalf("u.Rematch: want: %v - have: %v", ErrInvalidRematch, err)
+	}
+
+	opts := model.GameOptions{
+		TimeControl: model.TimeControl{Base: 300, Increment: 3},
+	}
+	_, err = startGame(u1, u2, opts)
+	if err != nil {
+		t.Fatalf("startGame: %s", err.Error())
+	}
+
+	u1.Client().LeaveGame()
+	// rematch is set after the game is done
+	time.Sleep(time.Millisecond * 10)
+
+	err = u2.Rematch()
+	if err != nil {
+		t.Fatalf("u.Rematch: %s", err.Error())
+	}
+
+	o := waitFor(t, ch1, model.OrRematch)
+	inv := model.InviteOrder{}
+	json.Unmarshal(o.Data, &inv)
+	if inv.Profile != u2.Profile {
+		t.Fatalf("rematch update does not have the opponent's profile")
+	}
+
+	err = u1.Rematch()
+	if err != nil {
+		t.Fatalf("u.Rematch: %s", err.Error())
+	}
+
+	waitFor(t, ch1, model.OrGame)
+	waitFor(t, ch2, model.OrGame)
+
+	g := u1.Client().Game()
+	if g == nil || g != u2.Client().Game() {
+		t.Fatalf("rematch does not start a new game")
+	}
+
+	if u1.Client().P1() || !u2.Client().P1() {
+		t.Fatalf("rematch does not swap colors")
+	}
+
+	if g.Options().TimeControl != opts.TimeControl {
+		t.Fatalf("rematch does not keep the time control")
+	}
+}
+
+func TestUserRematchHandicap(t *testing.T) {
+	u1, ch1 := newDrainedUser(t)
+	u2, ch2 := newDrainedUser(t)
+	defer u1.Delete()
+	defer u2.Delete()
+
+	// u1 gives a knight and extra time as player one
+	opts := model.GameOptions{
+		TimeControl: model.TimeControl{Base: 300},
+		Handicap:    &model.Handicap{Odds: model.OddsKnight, Time: 30, P1: true},
+	}
+	_, err := startGame(u1, u2, opts)
+	if err != nil {
+		t.Fatalf("startGame: %s", err.Error())
+	}
+
+	u1.Client().LeaveGame()
+	time.Sleep(time.Millisecond * 10)
+
+	u1.Rematch()
+	err = u2.Rematch()
+	if err != nil {
+		t.Fatalf("u.Rematch: %s", err.Error())
+	}
+
+	waitFor(t, ch1, model.OrGame)
+	waitFor(t, ch2, model.OrGame)
+
+	g := u1.Client().Game()
+	if g == nil || u1.Client().P1() {
+		t.Fatalf("rematch does not swap colors")
+	}
+
+	h := g.Options().Handicap
+	if h == nil || h.P1 || h.Odds != model.OddsKnight || h.Time != 30 {
+		t.Fatalf("handicap does not follow the player that gives it: %+v", h)
+	}
+	if !opts.Handicap.P1 {
+		t.Fatalf("rematch changes the previous game's handicap")
+	}
+
+	clk := g.Clock()
+	if clk.P1 <= 300000 || clk.P2 != 300000 {
+		t.Fatalf("extra time is not given to the weaker player: %+v", clk)
+	}
+}
+
+func TestUserRematchExpired(t *testing.T) {
+	u1, ch1 := newDrainedUser(t)
+	u2, _ := newDrainedUser(t)
+	defer u1.Delete()
+	defer u2.Delete()
+
+	_, err := startGame(u1, u2, model.GameOptions{})
+	if err != nil {
+		t.Fatalf("startGame: %s", err.Error())
+	}
+
+	u1.Client().LeaveGame()
+	time.Sleep(time.Millisecond * 10)
+
+	// RematchLifespan has passed since the game ended
+	u2.mtx.Lock()
+	u2.rematch.expires = time.Now().Add(-time.Second)
+	u2.mtx.Unlock()
+
+	err = u2.Rematch()
+	if err != ErrInvalidRematch {
+		t.Fatalf("u.Rematch: want: %v - have: %v", ErrInvalidRematch, err)
+	}
+
+	timeout := time.After(time.Millisecond * 50)
+	for {
+		select {
+		case o := <-ch1:
+			if o.ID == model.OrRematch {
+				t.Fatalf("opponent is notified of an expired rematch")
+			}
+		case <-timeout:
+			return
+		}
+	}
+}
+
+func TestCommandRematch(t *testing.T) {
+	u1, ch1 := newDrainedUser(t)
+	u2, _ := newDrainedUser(t)
+	defer u1.Delete()
+	defer u2.Delete()
+
+	_, err := startGame(u1, u2, model.GameOptions{})
+	if err != nil {
+		t.Fatalf("startGame: %s", err.Error())
+	}
+
+	u1.Client().LeaveGame()
+	time.Sleep(time.Millisecond * 10)
+
+	body, _ := json.Marshal(model.Order{
+		ID:   model.OrRematch,
+		Data: []byte("{}"),
+	})
+
+	resp := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/", bytes.NewBuffer(body))
+	req.Header.Set("Authorization", "Bearer "+u2.Token)
+	CmdHandler(resp, req)
+
+	if resp.Code != http.StatusOK {
+		t.Fatalf("CmdHandler: want: %d - have: %d - %s", http.StatusOK, resp.Code, resp.Body.String())
+	}
+
+	waitFor(t, ch1, model.OrRematch)
+}
@@ -0,0 +1,186 @@
+package rest
+
+import (
+	"math/rand"
+	"net/http"
+	"sync"
+
+	"github.com/toms1441/chess-server/internal/game"
+	"github.com/toms1441/chess-server/internal/model"
+	"github.com/toms1441/chess-server/internal/rating"
+)
+
+// seek.go pairs users looking for a game, without them having to invite each other.
+
+// seek is a seek waiting in the pool for a compatible seek.
+type seek struct {
+	u *User
+	s model.Seek
+}
+
+// seeks is the pool of seeks, oldest first. A user has at most one seek.
+var seeks = []*seek{}
+var seekmtx sync.Mutex
+
+// accepts returns true whenever the rating is within the seek's range.
+func accepts(s model.Seek, rating int) bool {
+	if s.MinRating > 0 && rating < s.MinRating {
+		return false
+	}
+	if s.MaxRating > 0 && rating > s.MaxRating {
+		return false
+	}
+
+	return true
+}
+
+// compatible returns true whenever both seeks could be paired in one game.
+func compatible(a, b *seek) bool {
+	if a.s.TimeControl != b.s.TimeControl || a.s.Variant != b.s.Variant || a.s.Rated != b.s.Rated {
+		return false
+	}
+	if blocked(a.u.Profile, b.u.Profile) {
+		return false
+	}
+
+	// both seeks have the same time control, so the same category
+	cat := rating.Category(model.GameOptions{TimeControl: a.s.TimeControl})
+	if !accepts(a.s, b.u.Rating(cat)) || !accepts(b.s, a.u.Rating(cat)) {
+		return false
+	}
+
+	// both want the same color
+	if len(a.s.Color) > 0 && a.s.Color != model.ColorRandom && a.s.Color == b.s.Color {
+		return false
+	}
+
+	return true
+}
+
+// white returns true whenever a plays white against b. The colors of compatible seeks are always satisfied.
+func white(a, b model.Seek) bool {
+	switch {
+	case a.Color == model.ColorWhite:
+		return true
+	case a.Color == model.ColorBlack:
+		return false
+	case b.Color == model.ColorWhite:
+		return false
+	case b.Color == model.ColorBlack:
+		return true
+	}
+
+	return rand.Intn(2) == 0
+}
+
+// Seek posts a seek, replacing the user's previous seek. If a compatible seek is in the pool, a game starts right away. Otherwise, the seek waits in the pool until it's paired or cancelled.
+func (u *User) Seek(s model.Seek) error {
+	if !u.Valid() {
+		return game.ErrClientNil
+	}
+	if u.playing() {
+		return game.ErrGameIsNotNil
+	}
+
+	switch s.Color {
+	case "", model.ColorWhite, model.ColorBlack, model.ColorRandom:
+	default:
+		return ErrInvalidSeek
+	}
+
+	if s.Variant == model.VariantStandard {
+		s.Variant = ""
+	}
+	if len(s.Variant) > 0 || s.TimeControl.Base < 0 || s.TimeControl.Increment < 0 {
+		return ErrInvalidSeek
+	}
+	if s.MaxRating > 0 && s.MinRating > s.MaxRating {
+		return ErrInvalidSeek
+	}
+
+	u.CancelSeek()
+
+	sk := &seek{u: u, s: s}
+
+	seekmtx.Lock()
+	var vs *seek
+	for k, v := range seeks {
+		if v.u != u && compatible(sk, v) {
+			vs = v
+			seeks = append(seeks[:k], seeks[k+1:]...)
+			break
+		}
+	}
+	if vs == nil {
+		seeks = append(seeks, sk)
+	}
+	seekmtx.Unlock()
+
+	if vs == nil {
+		return nil
+	}
+
+	opts := model.GameOptions{
+		Rated:       s.Rated,
+		TimeControl: s.TimeControl,
+	}
+
+	var err error
+	if white(s, vs.s) {
+		_, err = startGame(u, vs.u, opts)
+	} else {
+		_, err = startGame(vs.u, u, opts)
+	}
+
+	return err
+}
+
+// CancelSeek removes the user's seek from the pool, it's safe to call if the user has none.
+func (u *User) CancelSeek() {
+	seekmtx.Lock()
+	defer seekmtx.Unlock()
+
+	for k, v := range seeks {
+		if v.u == u {
+			seeks = append(seeks[:k], seeks[k+1:]...)
+			return
+		}
+	}
+}
+
+// SeekHandler posts the seek in the body, see User.Seek.
+func SeekHandler(w http.ResponseWriter, r *http.Request) {
+	u, err := GetUser(r)
+	if err != nil {
+		RespondError(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	s := model.Seek{}
+	err = BindJSON(r, &s)
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = u.Seek(s)
+	if err != nil {
+		RespondError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	RespondJSON(w, http.StatusOK, nil)
+}
+
+// CancelSeekHandler cancels the user's seek.
+func CancelSeekHandler(w http.ResponseWriter, r *http.Request) {
+	u, err := GetUser(r)
+	if err != nil {
+		RespondError(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	u.CancelSeek()
+
+	RespondJSON(w, http.StatusOK, nil)
+}
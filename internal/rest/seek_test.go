@@ -0,0 +1,67 @@
+package rest
+
+import (
+	"testing"
+
+	"github.com/toms1441/chess-server/internal/model"
+	"github.com/toms1441/chess-server/internal/rating"
+)
+
+func TestUserSeek(t *testing.T) {
+	u1, ch1 := newDrainedUser(t)
+	u2, _ := newDrainedUser(t)
+	u3, ch3 := newDrainedUser(t)
+	defer u1.Delete()
+	defer u3.Delete()
+
+	err := u1.Seek(model.Seek{Color: "purple"})
+	if err != ErrInvalidSeek {
+		t.Fatalf("u.Seek: want: %v - have: %v", ErrInvalidSeek, err)
+	}
+
+	blitz := model.TimeControl{Base: 300, Increment: 3}
+	err = u1.Seek(model.Seek{TimeControl: blitz, Color: model.ColorBlack})
+	if err != nil {
+		t.Fatalf("u.Seek: %s", err.Error())
+	}
+
+	// different time control
+	err = u2.Seek(model.Seek{TimeControl: model.TimeControl{Base: 60}})
+	if err != nil {
+		t.Fatalf("u.Seek: %s", err.Error())
+	}
+	// rating out of range
+	err = u3.Seek(model.Seek{TimeControl: blitz, MinRating: rating.DefaultRating + 1})
+	if err != nil {
+		t.Fatalf("u.Seek: %s", err.Error())
+	}
+
+	if u1.Client().Game() != nil || u2.Client().Game() != nil || u3.Client().Game() != nil {
+		t.Fatalf("incompatible seeks are paired")
+	}
+
+	err = u3.Seek(model.Seek{TimeControl: blitz})
+	if err != nil {
+		t.Fatalf("u.Seek: %s", err.Error())
+	}
+
+	waitFor(t, ch1, model.OrGame)
+	waitFor(t, ch3, model.OrGame)
+
+	g := u1.Client().Game()
+	if g == nil || g != u3.Client().Game() || g.Options().TimeControl != blitz {
+		t.Fatalf("compatible seeks are not paired")
+	}
+	if u1.Client().P1() {
+		t.Fatalf("seek does not keep the color")
+	}
+
+	// the paired seeks are removed, and u2's seek is cancelled once they disconnect
+	u2.disconnect(u2.Client().W)
+	seekmtx.Lock()
+	n := len(seeks)
+	seekmtx.Unlock()
+	if n != 0 {
+		t.Fatalf("seeks are not removed from the pool: %d", n)
+	}
+}
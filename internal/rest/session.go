@@ -0,0 +1,122 @@
+package rest
+
+import (
+	"io"
+	"sync"
+
+	"github.com/toms1441/chess-server/internal/game"
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+// session.go contains the connections of a user, as the same profile could be connected from several tabs or devices.
+
+// sessions is the writer of a user's clients, it writes every update to each of the user's connections.
+type sessions struct {
+	mtx sync.Mutex
+	ws  []io.WriteCloser
+}
+
+func newSessions(w io.WriteCloser) *sessions {
+	return &sessions{ws: []io.WriteCloser{w}}
+}
+
+func (s *sessions) Write(b []byte) (int, error) {
+	s.mtx.Lock()
+	ws := append([]io.WriteCloser{}, s.ws...)
+	s.mtx.Unlock()
+
+	for _, w := range ws {
+		w.Write(b)
+	}
+
+	return len(b), nil
+}
+
+// Close closes every connection.
+func (s *sessions) Close() error {
+	s.mtx.Lock()
+	ws := s.ws
+	s.ws = nil
+	s.mtx.Unlock()
+
+	for _, w := range ws {
+		w.Close()
+	}
+
+	return nil
+}
+
+// add adds w to the connections, unless it's already added.
+func (s *sessions) add(w io.WriteCloser) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	for _, v := range s.ws {
+		if v == w {
+			return
+		}
+	}
+
+	s.ws = append(s.ws, w)
+}
+
+// remove removes w from the connections, or every connection if w is s. It returns false if w isn't a connection, and the number of connections left.
+func (s *sessions) remove(w io.WriteCloser) (bool, int) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if w == s {
+		ok := len(s.ws) > 0
+		s.ws = nil
+		return ok, 0
+	}
+
+	for k, v := range s.ws {
+		if v == w {
+			s.ws = append(s.ws[:k], s.ws[k+1:]...)
+			return true, len(s.ws)
+		}
+	}
+
+	return false, len(s.ws)
+}
+
+// userByProfile returns the user with the same ID and platform as pro, or nil if there's none.
+func userByProfile(pro model.Profile) *User {
+	usermtx.Lock()
+	defer usermtx.Unlock()
+
+	for _, u := range users {
+		if u.cl != nil && u.Profile.ID == pro.ID && u.Profile.Platform == pro.Platform {
+			return u
+		}
+	}
+
+	return nil
+}
+
+// resume is called whenever the user opens a new connection w. A user that got disconnected from their games is reconnected, otherwise the state of their games is only sent to w.
+func (u *User) resume(w io.WriteCloser) error {
+	cl := u.Client()
+	if cl == nil {
+		return game.ErrClientNil
+	}
+
+	if !cl.Connected() {
+		err := u.reconnect(w)
+		if err != nil {
+			return err
+		}
+	} else {
+		for _, g := range u.Games() {
+			v := u.client(g.ID())
+			if v != nil {
+				v.Sync(w, opponentOf(g, v))
+			}
+		}
+	}
+
+	u.attach()
+
+	return nil
+}
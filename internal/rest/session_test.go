@@ -0,0 +1,52 @@
+package rest
+
+import (
+	"io"
+	"testing"
+
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+func TestUserSessions(t *testing.T) {
+	u1, ch1 := newDrainedUser(t)
+	u2, _ := newDrainedUser(t)
+	defer u1.Delete()
+	defer u2.Delete()
+
+	g, err := startGame(u1, u2, model.GameOptions{})
+	if err != nil {
+		t.Fatalf("startGame: %s", err.Error())
+	}
+
+	// the same profile connects from another tab
+	rd, wr := io.Pipe()
+	ch := drain(rd)
+	u, err := AddClient(u1.Profile, wr)
+	if err != nil {
+		t.Fatalf("AddClient: %s", err.Error())
+	}
+
+	if u != u1 {
+		t.Fatalf("another connection of the same profile creates another user")
+	}
+
+	err = u.resume(wr)
+	if err != nil {
+		t.Fatalf("u.resume: %s", err.Error())
+	}
+
+	o := waitFor(t, ch, model.OrGame)
+	if o.Game != g.ID() {
+		t.Fatalf("new connection does not receive the game")
+	}
+
+	g.SwitchTurn()
+	waitFor(t, ch1, model.OrTurn)
+	waitFor(t, ch, model.OrTurn)
+
+	// closing one connection keeps the user connected
+	u1.disconnect(wr)
+	if !u1.Client().Connected() || u1.Client().Game() != g {
+		t.Fatalf("user gets disconnected while having another connection")
+	}
+}
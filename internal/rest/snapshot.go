@@ -0,0 +1,162 @@
+package rest
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/toms1441/chess-server/internal/game"
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+// savedGame is a game in progress, along with the players' tokens so that they could reconnect after a restart.
+type savedGame struct {
+	Snapshot model.Snapshot `json:"snapshot"`
+	Token1   string         `json:"token1"`
+	Token2   string         `json:"token2"`
+	// Adjourned games don't have tokens, as they're resumed via User.Resume
+	Adjourned bool `json:"adjourned,omitempty"`
+}
+
+// userOf returns the user that has cl as their client.
+func userOf(cl *game.Client) *User {
+	if cl == nil {
+		return nil
+	}
+
+	var id string
+	if g := cl.Game(); g != nil {
+		id = g.ID()
+	}
+
+	usermtx.Lock()
+	defer usermtx.Unlock()
+	for _, u := range users {
+		if u.Client() == cl {
+			return u
+		}
+
+		u.mtx.Lock()
+		ok := u.games[id] == cl
+		u.mtx.Unlock()
+		if ok {
+			return u
+		}
+	}
+
+	return nil
+}
+
+// SaveGames writes every game in progress and every adjourned game to path, it should be called on graceful shutdown.
+func SaveGames(path string) error {
+	sl := []savedGame{}
+	for _, g := range game.Games() {
+		// correspondence games are saved after every move
+		if g.Correspondence() {
+			continue
+		}
+
+		u1, u2 := userOf(g.Player(true)), userOf(g.Player(false))
+		if u1 == nil || u2 == nil {
+			continue
+		}
+
+		sl = append(sl, savedGame{
+			Snapshot: g.Snapshot(),
+			Token1:   u1.Token,
+			Token2:   u2.Token,
+		})
+	}
+
+	for _, s := range game.AdjournedGames() {
+		sl = append(sl, savedGame{
+			Snapshot:  s,
+			Adjourned: true,
+		})
+	}
+
+	return writeFile(path, sl)
+}
+
+// writeFile writes v as json to a temporary file, then renames it to path so that a crash doesn't leave a partial file.
+func writeFile(path string, v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	tmp := path + ".tmp"
+	err = os.WriteFile(tmp, body, 0600)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, path)
+}
+
+// LoadGames restores the games saved via SaveGames, then removes path. The players have ReconnectLifespan to reconnect with their old tokens.
+// It's not an error if path doesn't exist.
+func LoadGames(path string) (int, error) {
+	body, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	sl := []savedGame{}
+	err = json.Unmarshal(body, &sl)
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	for _, v := range sl {
+		if v.Adjourned {
+			if game.AddAdjourned(v.Snapshot) == nil {
+				n++
+			}
+			continue
+		}
+
+		g, err := game.Restore(v.Snapshot)
+		if err != nil {
+			continue
+		}
+
+		u1 := restoreUser(v.Snapshot.P1, v.Token1, g.Player(true))
+		u2 := restoreUser(v.Snapshot.P2, v.Token2, g.Player(false))
+		watchGame(u1, u2, g)
+		n++
+	}
+
+	return n, os.Remove(path)
+}
+
+// restoreUser adds a disconnected user with their old token. Users that play several games are only added once.
+func restoreUser(profile model.Profile, token string, cl *game.Client) *User {
+	usermtx.Lock()
+	u, ok := users[token]
+	usermtx.Unlock()
+	if ok {
+		u.addGame(cl.Game().ID(), cl)
+		return u
+	}
+
+	u = &User{
+		invite: map[string]*pendingInvite{},
+		cl:     cl,
+		conns:  &sessions{},
+	}
+
+	u.Token = token
+	u.Profile = profile
+
+	usermtx.Lock()
+	users[token] = u
+	usermtx.Unlock()
+
+	u.waitReconnect()
+
+	return u
+}
@@ -0,0 +1,65 @@
+package rest
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/toms1441/chess-server/internal/game"
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+func TestSaveLoadGames(t *testing.T) {
+	u1, _ := newDrainedUser(t)
+	u2, _ := newDrainedUser(t)
+
+	g, err := startGame(u1, u2, model.GameOptions{})
+	if err != nil {
+		t.Fatalf("startGame: %s", err.Error())
+	}
+
+	path := filepath.Join(t.TempDir(), "games.json")
+	err = SaveGames(path)
+	if err != nil {
+		t.Fatalf("SaveGames: %s", err.Error())
+	}
+
+	// simulate a restart
+	id, tok1, tok2 := g.ID(), u1.Token, u2.Token
+	u1.Delete()
+	u2.Delete()
+
+	_, err = LoadGames(path)
+	if err != nil {
+		t.Fatalf("LoadGames: %s", err.Error())
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("LoadGames does not remove the snapshot file")
+	}
+
+	x, err := game.Get(id)
+	if err != nil {
+		t.Fatalf("game.Get: restored game is not registered")
+	}
+
+	r1, r2 := users[tok1], users[tok2]
+	if r1 == nil || r2 == nil {
+		t.Fatalf("restored users do not keep their tokens")
+	}
+	defer r1.Delete()
+	defer r2.Delete()
+
+	if r1.Client() != x.Player(true) {
+		t.Fatalf("restored user is not player one")
+	}
+
+	rd, wr := io.Pipe()
+	ch := drain(rd)
+	err = r1.reconnect(wr)
+	if err != nil {
+		t.Fatalf("u.reconnect: %s", err.Error())
+	}
+	waitFor(t, ch, model.OrHistory)
+}
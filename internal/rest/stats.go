@@ -0,0 +1,169 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/toms1441/chess-server/internal/game"
+	"github.com/toms1441/chess-server/internal/model"
+	"github.com/toms1441/chess-server/internal/rating"
+	"github.com/toms1441/chess-server/internal/stats"
+)
+
+// stats.go records finished games, and serves the leaderboards and the statistics of players computed from them.
+
+const (
+	// LeaderboardSize is the number of players in a leaderboard, unless ?n= is defined.
+	LeaderboardSize = 10
+	// MaxLeaderboardSize is the most players a leaderboard could have.
+	MaxLeaderboardSize = 100
+	// maxCacheEntries is the most responses a cacheBody keeps, since keys could be any profile in the path.
+	maxCacheEntries = 1024
+)
+
+// cacheBody caches responses by key, each response is rebuilt once it's older than cacheDuration. Expired responses are removed whenever a response is added, and the oldest one is removed whenever it's full.
+type cacheBody struct {
+	mtx   sync.Mutex
+	cache map[string]json.RawMessage
+	last  map[string]time.Time
+}
+
+// Get returns the cached response of key, or rebuilds it via fn.
+func (c *cacheBody) Get(key string, fn func() interface{}) json.RawMessage {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	now := time.Now().UTC()
+	if body, ok := c.cache[key]; ok && now.Sub(c.last[key]) < cacheDuration {
+		return body
+	}
+
+	c.evict(now)
+
+	body, _ := json.Marshal(fn())
+	c.cache[key] = body
+	c.last[key] = now
+
+	return body
+}
+
+// evict removes the expired responses, and the oldest response if there's no room for another one. It should be called with c.mtx locked.
+func (c *cacheBody) evict(now time.Time) {
+	oldest := ""
+	for k, v := range c.last {
+		if now.Sub(v) >= cacheDuration {
+			delete(c.cache, k)
+			delete(c.last, k)
+		} else if len(oldest) == 0 || v.Before(c.last[oldest]) {
+			oldest = k
+		}
+	}
+
+	if len(c.cache) >= maxCacheEntries {
+		delete(c.cache, oldest)
+		delete(c.last, oldest)
+	}
+}
+
+// Clear removes every cached response, they're rebuilt on the next call.
+func (c *cacheBody) Clear() {
+	c.mtx.Lock()
+	c.cache = map[string]json.RawMessage{}
+	c.last = map[string]time.Time{}
+	c.mtx.Unlock()
+}
+
+var leaderboards = cacheBody{
+	cache: map[string]json.RawMessage{},
+	last:  map[string]time.Time{},
+}
+
+var playerStats = cacheBody{
+	cache: map[string]json.RawMessage{},
+	last:  map[string]time.Time{},
+}
+
+// finish is called once the game ends, pro1 and pro2 are the profiles of the players. The result is stored, and the ratings are updated if the game is rated.
+func finish(g *game.Game, pro1, pro2 model.Profile) {
+	opts := g.Options()
+
+	stats.Add(model.Result{
+		ID:       g.ID(),
+		P1:       pro1,
+		P2:       pro2,
+		Reason:   g.Reason(),
+		Category: rating.Category(opts),
+		Rated:    opts.Rated,
+		Opening:  g.Opening(),
+		Moves:    (len(g.History()) + 1) / 2,
+		Ended:    time.Now().UTC(),
+	})
+
+	rate(g, pro1, pro2)
+
+	playerStats.Clear()
+	if opts.Rated {
+		leaderboards.Clear()
+	}
+}
+
+// LeaderboardHandler returns the top players of the category in the path, ?n= is the number of players.
+func LeaderboardHandler(w http.ResponseWriter, r *http.Request) {
+	_, err := GetUser(r)
+	if err != nil {
+		RespondError(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	cat := mux.Vars(r)["category"]
+	if !rating.ValidCategory(cat) {
+		RespondError(w, http.StatusNotFound, ErrInvalidCategory)
+		return
+	}
+
+	n := LeaderboardSize
+	if str := r.URL.Query().Get("n"); len(str) > 0 {
+		n, err = strconv.Atoi(str)
+		if err != nil || n <= 0 || n > MaxLeaderboardSize {
+			RespondError(w, http.StatusBadRequest, ErrLeaderboardSize)
+			return
+		}
+	}
+
+	body := leaderboards.Get(cat+":"+strconv.Itoa(n), func() interface{} {
+		return rating.Leaderboard(cat, n)
+	})
+
+	// the body is cached already, see RespondJSON
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
+
+// StatsHandler returns the statistics of the player with the platform and id in the path.
+func StatsHandler(w http.ResponseWriter, r *http.Request) {
+	_, err := GetUser(r)
+	if err != nil {
+		RespondError(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	vars := mux.Vars(r)
+	pro := model.Profile{
+		ID:       vars["id"],
+		Platform: vars["platform"],
+	}
+
+	body := playerStats.Get(pro.Platform+":"+pro.ID, func() interface{} {
+		return stats.Of(pro)
+	})
+
+	// the body is cached already, see RespondJSON
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(body)
+}
@@ -0,0 +1,114 @@
+package rest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/toms1441/chess-server/internal/model"
+	"github.com/toms1441/chess-server/internal/rating"
+	"github.com/toms1441/chess-server/internal/stats"
+)
+
+func TestStatsHandler(t *testing.T) {
+	rating.Reset()
+	stats.Reset()
+	defer rating.Reset()
+	defer stats.Reset()
+
+	u1, ch1 := newDrainedUser(t)
+	u2, ch2 := newDrainedUser(t)
+	defer u1.Delete()
+	defer u2.Delete()
+
+	bullet := model.TimeControl{Base: 60}
+	err := u1.Seek(model.Seek{TimeControl: bullet, Rated: true, Color: model.ColorWhite})
+	if err != nil {
+		t.Fatalf("u.Seek: %s", err.Error())
+	}
+	err = u2.Seek(model.Seek{TimeControl: bullet, Rated: true})
+	if err != nil {
+		t.Fatalf("u.Seek: %s", err.Error())
+	}
+
+	waitFor(t, ch1, model.OrGame)
+	waitFor(t, ch2, model.OrGame)
+
+	// white resigns
+	err = u1.Client().Do(model.Order{ID: model.OrDone})
+	if err != nil {
+		t.Fatalf("cl.Do: %s", err.Error())
+	}
+	waitForRatings(t, ch1)
+
+	rout := mux.NewRouter()
+	rout.HandleFunc("/leaderboard/{category}", LeaderboardHandler)
+	rout.HandleFunc("/users/{platform}/{id}/stats", StatsHandler)
+
+	get := func(path string, v interface{}) int {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Add("Authorization", u1.Token)
+
+		resp := httptest.NewRecorder()
+		rout.ServeHTTP(resp, req)
+
+		if v != nil && resp.Code == http.StatusOK {
+			if resp.Header().Get("Content-Type") != "application/json" {
+				t.Fatalf("bad content type")
+			}
+			if err := json.Unmarshal(resp.Body.Bytes(), v); err != nil {
+				t.Fatalf("json.Unmarshal: %s", err.Error())
+			}
+		}
+
+		return resp.Code
+	}
+
+	sl := []model.Ranking{}
+	if code := get("/leaderboard/bullet", &sl); code != http.StatusOK {
+		t.Fatalf("status: %d", code)
+	}
+	if len(sl) != 2 || sl[0].ID != u2.Profile.ID || sl[0].Rank != 1 {
+		t.Fatalf("unexpected leaderboard: %+v", sl)
+	}
+
+	if code := get("/leaderboard/bughouse", nil); code != http.StatusNotFound {
+		t.Fatalf("status: %d - want: %d", code, http.StatusNotFound)
+	}
+	if code := get("/leaderboard/bullet?n=0", nil); code != http.StatusBadRequest {
+		t.Fatalf("status: %d - want: %d", code, http.StatusBadRequest)
+	}
+
+	st := model.Stats{}
+	if code := get("/users/"+u1.Profile.Platform+"/"+u1.Profile.ID+"/stats", &st); code != http.StatusOK {
+		t.Fatalf("status: %d", code)
+	}
+	if st.Games != 1 || st.Losses != 1 || len(st.Form) != 1 || st.Form[0] != model.FormLoss {
+		t.Fatalf("unexpected stats: %+v", st)
+	}
+}
+
+func TestCacheBodyEvict(t *testing.T) {
+	c := cacheBody{
+		cache: map[string]json.RawMessage{},
+		last:  map[string]time.Time{},
+	}
+
+	c.Get("old", func() interface{} { return nil })
+	c.last["old"] = time.Now().UTC().Add(-cacheDuration)
+
+	for i := 0; i < maxCacheEntries+10; i++ {
+		c.Get(strconv.Itoa(i), func() interface{} { return i })
+	}
+
+	if len(c.cache) != maxCacheEntries || len(c.last) != maxCacheEntries {
+		t.Fatalf("cache is not bounded: %d", len(c.cache))
+	}
+	if _, ok := c.cache["old"]; ok {
+		t.Fatalf("expired response is not removed")
+	}
+}
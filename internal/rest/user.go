@@ -10,15 +10,29 @@ import (
 	"time"
 
 	"github.com/kjk/betterguid"
+	"github.com/toms1441/chess-server/internal/friends"
 	"github.com/toms1441/chess-server/internal/game"
 	"github.com/toms1441/chess-server/internal/model"
+	"github.com/toms1441/chess-server/internal/puzzle"
+	"github.com/toms1441/chess-server/internal/rating"
 )
 
 type User struct {
 	model.CredentialsOrder
 	mtx    sync.Mutex
-	invite map[string]*User
+	invite map[string]*pendingInvite
 	cl     *game.Client
+	puzzle *puzzle.Session
+	// rematch is set whenever a game ends
+	rematch *rematch
+	// reconnectTimer deletes the user whenever they don't reconnect in time
+	reconnectTimer *time.Timer
+	// games are the clients of the games the user plays or spectates besides cl, keyed by the game's ID
+	games map[string]*game.Client
+	// conns are the user's connections, which is the writer of the user's clients
+	conns *sessions
+	// lastInvite is when the user last invited someone, see InviteInterval
+	lastInvite time.Time
 }
 
 var users = map[string]*User{}
@@ -38,17 +52,26 @@ func ClientChannel() chan *User {
 	return chanuser
 }
 
+// AddClient adds a user with the connection wc. If a user with the same profile exists, wc is added to their connections instead, and they keep their token.
 func AddClient(profile model.Profile, wc io.WriteCloser) (*User, error) {
 	if wc == nil || !profile.Valid() {
 		return nil, fmt.Errorf("one of the parameters is nil")
 	}
 
+	if u := userByProfile(profile); u != nil {
+		u.conns.add(wc)
+		return u, nil
+	}
+
 	id := betterguid.New()
+	conns := newSessions(wc)
 	us := &User{
-		invite: map[string]*User{},
+		invite: map[string]*pendingInvite{},
 		cl: &game.Client{
-			W: wc,
+			W:       conns,
+			Profile: profile,
 		},
+		conns: conns,
 	}
 
 	us.Token = id
@@ -60,6 +83,7 @@ func AddClient(profile model.Profile, wc io.WriteCloser) (*User, error) {
 	go func() {
 		chanuser <- us
 	}()
+	go notifyFriends(profile, model.OrFriendOnline)
 
 	return us, nil
 }
@@ -84,16 +108,22 @@ func GetAvaliableUsersHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// ?friends=true only lists the user's friends
+	onlyFriends := r.URL.Query().Get("friends") == "true"
+
 	ids := []json.RawMessage{}
 	for _, v := range users {
 		// lamo you can't invite yourself
 		if v.Profile.ID == u.Profile.ID && v.Profile.Platform == u.Profile.Platform {
 			continue
 		}
+		if blocked(u.Profile, v.Profile) || (onlyFriends && !friends.IsFriend(u.Profile, v.Profile)) {
+			continue
+		}
 
 		if v.Valid() {
 			if v.Client().Game() == nil {
-				body, err := json.Marshal(v.Profile)
+				body, err := json.Marshal(rating.Rated(v.Profile))
 				if err != nil {
 					RespondError(w, http.StatusInternalServerError, err)
 					return
@@ -112,6 +142,17 @@ func (u *User) Client() *game.Client {
 
 func (u *User) Delete() {
 	id := u.Token
+	pro := u.Profile
+
+	u.mtx.Lock()
+	if u.reconnectTimer != nil {
+		u.reconnectTimer.Stop()
+		u.reconnectTimer = nil
+	}
+	u.mtx.Unlock()
+
+	u.leave()
+	u.CancelSeek()
 
 	u.Profile = model.Profile{}
 	u.Token = ""
@@ -119,11 +160,18 @@ func (u *User) Delete() {
 		u.cl.LeaveGame()
 	}
 	u.cl = nil
+	u.games = nil
 	u.invite = nil
+	u.puzzle = nil
+	u.rematch = nil
 
 	usermtx.Lock()
 	delete(users, id)
 	usermtx.Unlock()
+
+	if pro.Valid() {
+		go notifyFriends(pro, model.OrFriendOffline)
+	}
 }
 
 func (u *User) Valid() bool {
@@ -7,9 +7,9 @@ import (
 	"sync"
 	"time"
 
-	"github.com/kjk/betterguid"
 	"github.com/toms1441/chess-server/internal/game"
 	"github.com/toms1441/chess-server/internal/model"
+	"github.com/toms1441/chess-server/internal/rating"
 )
 
 var cacheDuration = time.Minute
@@ -22,10 +22,16 @@ type watchableModel struct {
 
 func (w *watchableModel) MarshalJSON() ([]byte, error) {
 	if w != nil && w.gm != nil {
-		return json.Marshal(model.Watchable{
-			P1:  w.p1,
-			P2:  w.p2,
-			Brd: w.gm.Board(),
+		return json.Marshal(model.RatedWatchable{
+			Watchable: model.Watchable{
+				ID:      w.gm.ID(),
+				P1:      w.p1,
+				P2:      w.p2,
+				Brd:     w.gm.Board(),
+				Opening: w.gm.Opening(),
+			},
+			P1: rating.Rated(w.p1),
+			P2: rating.Rated(w.p2),
 		})
 	}
 
@@ -39,8 +45,9 @@ type cacheWatchable struct {
 	last  time.Time
 }
 
+// Add adds the game to the list of watchable games, it's indexed by the game's ID.
 func (c *cacheWatchable) Add(m watchableModel) string {
-	id := betterguid.New()
+	id := m.gm.ID()
 
 	watchable.mtx.Lock()
 	c.slice[id] = &m
@@ -119,14 +126,23 @@ func WatchableJoinHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if u.client(generic.ID) != nil {
+		RespondError(w, http.StatusBadRequest, game.ErrGameIsNotNil)
+		return
+	}
+
 	RespondJSON(w, http.StatusOK, model.Watchable{
-		P1: sl.p1,
-		P2: sl.p2,
+		ID:      sl.gm.ID(),
+		P1:      sl.p1,
+		P2:      sl.p2,
+		Opening: sl.gm.Opening(),
 	})
 
-	g := sl.gm
-	g.AddSpectator(u.Client())
-
+	// spectators get their own client, so that they could play while spectating
+	cl := u.newClient()
+	if sl.gm.AddSpectator(cl) == nil {
+		u.addGame(sl.gm.ID(), cl)
+	}
 }
 
 func WatchableLeaveHandler(w http.ResponseWriter, r *http.Request) {
@@ -136,20 +152,26 @@ func WatchableLeaveHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	cl := u.Client()
-	if cl == nil {
-		RespondError(w, http.StatusUnauthorized, game.ErrClientNil)
-		return
+	// leave the spectated game with ?game=, or every spectated game if it's empty
+	id := r.URL.Query().Get("game")
+
+	left := false
+	for k, cl := range u.clients() {
+		g := cl.Game()
+		if g.IsPlayer(cl) || (len(id) > 0 && k != id) {
+			continue
+		}
+
+		g.RmSpectator(cl)
+		u.rmGame(k)
+		left = true
 	}
 
-	g := u.Client().Game()
-	if g == nil {
+	if !left {
 		RespondError(w, http.StatusUnauthorized, game.ErrGameNil)
 		return
 	}
 
-	g.RmSpectator(u.Client())
-
 	w.WriteHeader(http.StatusOK)
 	w.Write(nil)
 }
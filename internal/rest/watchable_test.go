@@ -86,8 +86,11 @@ func TestWatchableWatchHandler(t *testing.T) {
 		<-read(rd2)
 	}()
 
+	resetInvite(us1)
+	// the colors are fixed, as updates are read in order
 	err := us1.Invite(model.InviteOrder{
 		Profile: us2.Profile,
+		Color:   model.ColorBlack,
 	}, InviteLifespan)
 	if err != nil {
 		t.Fatalf("us1.Invite: %s", err.Error())
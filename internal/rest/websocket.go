@@ -64,7 +64,7 @@ func (cl *WsClient) CloseWithReason(status ws.StatusCode, reason string) error {
 		}
 	}()
 
-	cl.u.Delete()
+	cl.u.disconnect(cl)
 	cl.Conn.Close()
 
 	return nil
@@ -80,16 +80,23 @@ func (cl *WsClient) Write(b []byte) (n int, err error) {
 	return 0, nil
 }
 
-func UpgradeConn(profile model.Profile, conn net.Conn) (*WsClient, error) {
+func newWsClient(conn net.Conn) (*WsClient, error) {
 	if conn == nil {
 		return nil, fmt.Errorf("conn is nil")
 	}
 
-	cl := &WsClient{
+	return &WsClient{
 		Conn: conn,
 		W:    make(chan []byte, 8),
 		c:    []chan bool{},
 		r:    []chan []byte{},
+	}, nil
+}
+
+func UpgradeConn(profile model.Profile, conn net.Conn) (*WsClient, error) {
+	cl, err := newWsClient(conn)
+	if err != nil {
+		return nil, err
 	}
 
 	u, err := AddClient(profile, cl)
@@ -97,6 +104,36 @@ func UpgradeConn(profile model.Profile, conn net.Conn) (*WsClient, error) {
 		return nil, err
 	}
 
+	err = cl.serve(u)
+	if err != nil {
+		return nil, err
+	}
+
+	return cl, u.resume(cl)
+}
+
+// ReconnectConn binds a user that got disconnected from their game to a new connection. The user keeps their token, and receives the game's state after model.OrCredentials.
+func ReconnectConn(u *User, conn net.Conn) (*WsClient, error) {
+	if u.Client() == nil || u.Client().Connected() {
+		return nil, ErrInvalidReconnect
+	}
+
+	cl, err := newWsClient(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	err = cl.serve(u)
+	if err != nil {
+		return nil, err
+	}
+
+	return cl, u.resume(cl)
+}
+
+// serve starts reading and writing to the connection, and sends the user's credentials.
+func (cl *WsClient) serve(u *User) error {
+	conn := cl.Conn
 	cl.u = u
 
 	// read any close messages
@@ -181,12 +218,7 @@ func UpgradeConn(profile model.Profile, conn net.Conn) (*WsClient, error) {
 		close(ch)
 	}(u, ch)
 
-	err = <-ch
-	if err != nil {
-		return nil, err
-	}
-
-	return cl, nil
+	return <-ch
 }
 
 func WebsocketHandler(w http.ResponseWriter, r *http.Request) {
@@ -196,12 +228,36 @@ func WebsocketHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// reconnect to a game in progress
+	var u *User
+	if tok := r.URL.Query().Get("token"); len(tok) > 0 {
+		usermtx.Lock()
+		u = users[tok]
+		usermtx.Unlock()
+
+		if u == nil || u.Client() == nil || u.Client().Connected() {
+			RespondError(w, http.StatusBadRequest, ErrInvalidReconnect)
+			return
+		}
+
+		// the token is not enough, it has to be the same player
+		if authuser.ID != u.Profile.ID || authuser.Platform != u.Profile.Platform {
+			RespondError(w, http.StatusUnauthorized, ErrInvalidReconnect)
+			return
+		}
+	}
+
 	conn, _, _, err := ws.UpgradeHTTP(r, w)
 	if err != nil {
 		RespondError(w, http.StatusBadRequest, err)
 		return
 	}
 
+	if u != nil {
+		ReconnectConn(u, conn)
+		return
+	}
+
 	UpgradeConn(*authuser, conn)
 }
 
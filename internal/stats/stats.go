@@ -0,0 +1,195 @@
+// Package stats provides the store of finished games, and the statistics of players computed from it.
+//
+// Only games that have a result are stored, so adjourned games aren't counted until they're resumed and finished.
+package stats
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/toms1441/chess-server/internal/model"
+	"github.com/toms1441/chess-server/internal/rating"
+)
+
+const (
+	// MaxOpenings is the number of openings in a player's statistics.
+	MaxOpenings = 3
+	// MaxForm is the number of results in a player's recent form.
+	MaxForm = 10
+)
+
+// File is where results are appended after every game, one per line. Empty means they're only kept in memory.
+var File string
+
+// filemtx keeps writes to File from interleaving.
+var filemtx sync.Mutex
+
+var (
+	mtx sync.RWMutex
+	// results are ordered by the time the game ended, oldest first
+	results = []model.Result{}
+)
+
+func same(a, b model.Profile) bool {
+	return a.ID == b.ID && a.Platform == b.Platform
+}
+
+// Add stores the result of a finished game. It's ignored if the game doesn't have a result.
+func Add(r model.Result) {
+	if _, ok := rating.Score(r.Reason); !ok {
+		return
+	}
+
+	mtx.Lock()
+	results = append(results, r)
+	mtx.Unlock()
+
+	save(r)
+}
+
+// Of returns the statistics of the profile's finished games.
+func Of(pro model.Profile) model.Stats {
+	st := model.Stats{
+		Openings: []model.OpeningStats{},
+		Form:     []string{},
+	}
+
+	openings := map[model.Opening]int{}
+	moves := 0
+
+	mtx.RLock()
+	for i := len(results) - 1; i >= 0; i-- {
+		r := results[i]
+
+		var p1 bool
+		switch {
+		case same(r.P1, pro):
+			p1 = true
+		case same(r.P2, pro):
+			p1 = false
+		default:
+			continue
+		}
+
+		score, _ := rating.Score(r.Reason)
+		if !p1 {
+			score = 1 - score
+		}
+
+		form := model.FormDraw
+		switch score {
+		case 1:
+			st.Wins++
+			form = model.FormWin
+		case 0:
+			st.Losses++
+			form = model.FormLoss
+		default:
+			st.Draws++
+		}
+
+		if len(st.Form) < MaxForm {
+			st.Form = append(st.Form, form)
+		}
+
+		if r.Opening != nil {
+			openings[*r.Opening]++
+		}
+
+		st.Games++
+		moves += r.Moves
+	}
+	mtx.RUnlock()
+
+	if st.Games > 0 {
+		st.AverageMoves = float64(moves) / float64(st.Games)
+	}
+
+	for o, n := range openings {
+		st.Openings = append(st.Openings, model.OpeningStats{Opening: o, Games: n})
+	}
+	sort.Slice(st.Openings, func(i, j int) bool {
+		if st.Openings[i].Games != st.Openings[j].Games {
+			return st.Openings[i].Games > st.Openings[j].Games
+		}
+		return st.Openings[i].ECO < st.Openings[j].ECO
+	})
+	if len(st.Openings) > MaxOpenings {
+		st.Openings = st.Openings[:MaxOpenings]
+	}
+
+	return st
+}
+
+// Reset removes every result, it's used by tests.
+func Reset() {
+	mtx.Lock()
+	results = []model.Result{}
+	mtx.Unlock()
+}
+
+// save appends r to File, so that finishing a game doesn't rewrite every result.
+func save(r model.Result) error {
+	if len(File) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+
+	filemtx.Lock()
+	defer filemtx.Unlock()
+
+	f, err := os.OpenFile(File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	_, err = f.Write(append(body, '\n'))
+	if err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// Load reads the results saved in path, and saves them to path from now on. It's not an error if path doesn't exist.
+func Load(path string) error {
+	File = path
+
+	body, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	sl := []model.Result{}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	for {
+		r := model.Result{}
+		err = dec.Decode(&r)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		sl = append(sl, r)
+	}
+
+	mtx.Lock()
+	results = sl
+	mtx.Unlock()
+
+	return nil
+}
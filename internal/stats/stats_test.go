@@ -0,0 +1,65 @@
+package stats
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/toms1441/chess-server/internal/model"
+)
+
+func TestOf(t *testing.T) {
+	Reset()
+	defer Reset()
+
+	File = filepath.Join(t.TempDir(), "results.json")
+	defer func() { File = "" }()
+
+	p1 := model.Profile{ID: "1", Platform: "local"}
+	p2 := model.Profile{ID: "2", Platform: "local"}
+	p3 := model.Profile{ID: "3", Platform: "local"}
+
+	italian := &model.Opening{ECO: "C50", Name: "Italian Game"}
+	sicilian := &model.Opening{ECO: "B20", Name: "Sicilian Defense"}
+
+	Add(model.Result{P1: p1, P2: p2, Reason: model.DoneWhiteMates, Opening: italian, Moves: 20})
+	Add(model.Result{P1: p2, P2: p1, Reason: model.DoneWhiteTimeout, Opening: sicilian, Moves: 40})
+	Add(model.Result{P1: p1, P2: p3, Reason: model.DoneStalemate, Opening: italian, Moves: 60})
+	Add(model.Result{P1: p1, P2: p3, Reason: model.DoneBlackForfeit, Moves: 1})
+	// no result
+	Add(model.Result{P1: p1, P2: p3, Reason: model.DoneAdjourned, Moves: 30})
+
+	st := Of(p1)
+	if st.Games != 4 || st.Wins != 3 || st.Losses != 0 || st.Draws != 1 {
+		t.Fatalf("unexpected results: %+v", st)
+	}
+	if st.AverageMoves != 30.25 {
+		t.Fatalf("average moves: %f", st.AverageMoves)
+	}
+	if len(st.Openings) != 2 || st.Openings[0].ECO != italian.ECO || st.Openings[0].Games != 2 {
+		t.Fatalf("unexpected openings: %+v", st.Openings)
+	}
+
+	form := []string{model.FormWin, model.FormDraw, model.FormWin, model.FormWin}
+	if len(st.Form) != len(form) {
+		t.Fatalf("unexpected form: %v", st.Form)
+	}
+	for k := range form {
+		if st.Form[k] != form[k] {
+			t.Fatalf("unexpected form: %v", st.Form)
+		}
+	}
+
+	st = Of(p2)
+	if st.Games != 2 || st.Losses != 2 {
+		t.Fatalf("unexpected results: %+v", st)
+	}
+
+	Reset()
+	err := Load(File)
+	if err != nil {
+		t.Fatalf("Load: %s", err.Error())
+	}
+	if Of(p3).Games != 2 {
+		t.Fatalf("results weren't saved: %+v", Of(p3))
+	}
+}
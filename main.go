@@ -5,16 +5,23 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
+	"time"
 
 	"github.com/fatih/color"
 	"github.com/gorilla/mux"
+	"github.com/toms1441/chess-server/internal/friends"
 	"github.com/toms1441/chess-server/internal/model"
 	"github.com/toms1441/chess-server/internal/model/discord"
 	"github.com/toms1441/chess-server/internal/model/github"
 	"github.com/toms1441/chess-server/internal/model/google"
+	"github.com/toms1441/chess-server/internal/puzzle"
+	"github.com/toms1441/chess-server/internal/rating"
 	"github.com/toms1441/chess-server/internal/rest"
 	"github.com/toms1441/chess-server/internal/rest/auth"
+	"github.com/toms1441/chess-server/internal/stats"
 )
 
 const apiver = "v1"
@@ -31,9 +38,34 @@ func main() {
 		api.HandleFunc("/cmd", rest.CmdHandler).Methods("POST", "OPTIONS")
 		api.HandleFunc("/invite", rest.InviteHandler).Methods("POST", "OPTIONS")
 		api.HandleFunc("/accept", rest.AcceptInviteHandler).Methods("POST", "OPTIONS")
+		api.HandleFunc("/invite/decline", rest.DeclineInviteHandler).Methods("POST", "OPTIONS")
+		api.HandleFunc("/invite/cancel", rest.CancelInviteHandler).Methods("POST", "OPTIONS")
+		api.HandleFunc("/invites", rest.InvitesHandler).Methods("GET", "OPTIONS")
+		api.HandleFunc("/challenge", rest.ChallengeHandler).Methods("POST", "OPTIONS")
+		api.HandleFunc("/challenge/{code}", rest.GetChallengeHandler).Methods("GET", "OPTIONS")
+		api.HandleFunc("/challenge/{code}/accept", rest.AcceptChallengeHandler).Methods("POST", "OPTIONS")
+		api.HandleFunc("/challenge/{code}/cancel", rest.CancelChallengeHandler).Methods("POST", "OPTIONS")
+		api.HandleFunc("/rematch", rest.RematchHandler).Methods("POST", "OPTIONS")
+		api.HandleFunc("/seek", rest.SeekHandler).Methods("POST", "OPTIONS")
+		api.HandleFunc("/seek/cancel", rest.CancelSeekHandler).Methods("POST", "OPTIONS")
+		api.HandleFunc("/adjourned", rest.AdjournedHandler).Methods("GET", "OPTIONS")
+		api.HandleFunc("/resume", rest.ResumeHandler).Methods("POST", "OPTIONS")
+		api.HandleFunc("/correspondence", rest.CorrespondenceHandler).Methods("GET", "OPTIONS")
+		api.HandleFunc("/games", rest.GamesHandler).Methods("GET", "OPTIONS")
+		api.HandleFunc("/game", rest.GameHandler).Methods("GET", "OPTIONS")
 		api.HandleFunc("/ws", rest.WebsocketHandler).Methods("GET", "OPTIONS")
 		api.HandleFunc("/avali", rest.GetAvaliableUsersHandler).Methods("GET", "OPTIONS")
+		api.HandleFunc("/friends", rest.FriendsHandler).Methods("GET", "OPTIONS")
+		api.HandleFunc("/friends/add", rest.FollowHandler).Methods("POST", "OPTIONS")
+		api.HandleFunc("/friends/remove", rest.UnfollowHandler).Methods("POST", "OPTIONS")
+		api.HandleFunc("/block", rest.BlockHandler).Methods("POST", "OPTIONS")
+		api.HandleFunc("/unblock", rest.UnblockHandler).Methods("POST", "OPTIONS")
 		api.HandleFunc("/possib", rest.PossibHandler).Methods("POST", "OPTIONS")
+		api.HandleFunc("/puzzle", rest.PuzzleHandler).Methods("GET", "OPTIONS")
+		api.HandleFunc("/puzzle/move", rest.PuzzleMoveHandler).Methods("POST", "OPTIONS")
+		api.HandleFunc("/puzzle/record", rest.PuzzleRecordHandler).Methods("GET", "OPTIONS")
+		api.HandleFunc("/leaderboard/{category}", rest.LeaderboardHandler).Methods("GET", "OPTIONS")
+		api.HandleFunc("/users/{platform}/{id}/stats", rest.StatsHandler).Methods("GET", "OPTIONS")
 
 		// is connected to ws?
 		api.HandleFunc("/connected", func(w http.ResponseWriter, r *http.Request) {
@@ -85,6 +117,26 @@ func main() {
 	addplatform("google", google.NewAuthConfig)
 	addplatform("github", github.NewAuthConfig)
 
+	if path := os.Getenv("PUZZLE_FILE"); len(path) > 0 {
+		err := puzzle.Load(path)
+		if err != nil {
+			panic(err)
+		}
+
+		color.New(color.FgBlue).Println("Loaded", puzzle.Len(), "puzzles")
+	}
+
+	if str := os.Getenv("CHALLENGE_LIFESPAN"); len(str) > 0 {
+		d, err := time.ParseDuration(str)
+		if err != nil {
+			panic(err)
+		}
+		rest.ChallengeLifespan = d
+	}
+	if url := os.Getenv("CHALLENGE_URL"); len(url) > 0 {
+		rest.ChallengeURL = url
+	}
+
 	var proto string
 	var port string
 	if debug != "no" {
@@ -110,6 +162,63 @@ func main() {
 
 	defer listen.Close()
 
+	// ratings are saved after every rated game
+	if path := os.Getenv("RATINGS_FILE"); len(path) > 0 {
+		err := rating.Load(path)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	// results are appended after every game
+	if path := os.Getenv("RESULTS_FILE"); len(path) > 0 {
+		err := stats.Load(path)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	// friends are saved after every change
+	if path := os.Getenv("FRIENDS_FILE"); len(path) > 0 {
+		err := friends.Load(path)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	// correspondence games are saved after every move
+	if path := os.Getenv("CORRESPONDENCE_FILE"); len(path) > 0 {
+		n, err := rest.LoadCorrespondence(path)
+		if err != nil {
+			panic(err)
+		}
+
+		color.New(color.FgBlue).Println("Restored", n, "correspondence games")
+	}
+
+	// games in progress are saved on shutdown, and restored on startup
+	if path := os.Getenv("SNAPSHOT_FILE"); len(path) > 0 {
+		n, err := rest.LoadGames(path)
+		if err != nil {
+			panic(err)
+		}
+
+		color.New(color.FgBlue).Println("Restored", n, "games")
+
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+		go func() {
+			<-sig
+			err := rest.SaveGames(path)
+			if err != nil {
+				color.New(color.FgRed).Println("Could not save games:", err)
+			}
+
+			listen.Close()
+			os.Exit(0)
+		}()
+	}
+
 	http.Serve(listen, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx := &rest.Context{
 			ResponseWriter: w,